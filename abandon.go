@@ -0,0 +1,122 @@
+package lockfreequeue
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+)
+
+// Abandoned 描述一个疑似被遗忘的队列。
+type Abandoned struct {
+	Name  string
+	Depth uint64
+	// Idle 是队列上一次出队至今的时间；Unreachable 为 true 时为 0。
+	Idle time.Duration
+	// Unreachable 为 true 表示队列在非空时被垃圾回收，其中的元素已经丢失。
+	Unreachable bool
+}
+
+type abandonState struct {
+	name   string
+	head   unsafe.Pointer
+	depth  uint64
+	since  time.Time
+	warned bool
+}
+
+// AbandonDetector 周期性扫描登记的队列，当某个队列超过 IdleAfter 没有任何出队、同时长度仍在增长时调用 OnAbandoned，
+// 用于在大型代码库中发现忘记启动或已经退出的消费者。
+//
+// 是否发生过出队通过队列头指针是否移动来判断，不会给出队路径增加任何开销；
+// 每次报警后，队列需要再次出现出队才会重新计时。
+type AbandonDetector struct {
+	// Interval 是扫描间隔，为 0 时使用 1 分钟。
+	Interval time.Duration
+	// IdleAfter 是没有出队多久之后开始报警，为 0 时使用 5 分钟。
+	IdleAfter time.Duration
+	// OnAbandoned 在检测到疑似被遗忘的队列时被调用。
+	OnAbandoned func(Abandoned)
+
+	mu      sync.Mutex
+	watched map[*Queue]*abandonState
+}
+
+// NewAbandonDetector 创建一个 AbandonDetector。
+func NewAbandonDetector(onAbandoned func(Abandoned)) *AbandonDetector {
+	return &AbandonDetector{OnAbandoned: onAbandoned, watched: make(map[*Queue]*abandonState)}
+}
+
+// Watch 开始监视 q，name 用于在报警中标识队列。
+// 检测器会持有 q 的引用，不再需要监视时应调用 Unwatch。
+func (d *AbandonDetector) Watch(q *Queue, name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.watched[q] = &abandonState{name: name, head: atomic.LoadPointer(&q.head), depth: q.Length()}
+}
+
+// Unwatch 停止监视 q。
+func (d *AbandonDetector) Unwatch(q *Queue) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delete(d.watched, q)
+}
+
+// Run 按 Interval 持续扫描，直到 ctx 结束。
+func (d *AbandonDetector) Run(ctx context.Context) error {
+	interval := d.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctxErr(ctx)
+		case now := <-t.C:
+			d.Observe(now)
+		}
+	}
+}
+
+// Observe 在时刻 now 扫描一次全部登记的队列。Run 会自动调用它，也可以由调用方自行驱动。
+func (d *AbandonDetector) Observe(now time.Time) {
+	idleAfter := d.IdleAfter
+	if idleAfter <= 0 {
+		idleAfter = 5 * time.Minute
+	}
+	var found []Abandoned
+	d.mu.Lock()
+	for q, st := range d.watched {
+		head, depth := atomic.LoadPointer(&q.head), q.Length()
+		if st.since.IsZero() || head != st.head {
+			// 首次扫描，或者自上次扫描以来发生过出队
+			st.head, st.depth, st.since, st.warned = head, depth, now, false
+			continue
+		}
+		if !st.warned && now.Sub(st.since) >= idleAfter && depth > st.depth {
+			st.warned = true
+			found = append(found, Abandoned{Name: st.name, Depth: depth, Idle: now.Sub(st.since)})
+		}
+	}
+	d.mu.Unlock()
+	if d.OnAbandoned != nil {
+		for _, a := range found {
+			d.OnAbandoned(a)
+		}
+	}
+}
+
+// WarnIfCollected 在 q 被垃圾回收时检查它是否仍然非空，若是则以 Unreachable 为 true 调用 warn，
+// 用于发现被整个丢弃而没有排空的队列。
+// 它通过 runtime.SetFinalizer 实现，会覆盖 q 上已有的 finalizer；warn 在 finalizer goroutine 中运行，不能阻塞。
+func WarnIfCollected(q *Queue, name string, warn func(Abandoned)) {
+	runtime.SetFinalizer(q, func(q *Queue) {
+		if depth := q.Length(); depth > 0 {
+			warn(Abandoned{Name: name, Depth: depth, Unreachable: true})
+		}
+	})
+}
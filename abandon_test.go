@@ -0,0 +1,71 @@
+package lockfreequeue_test
+
+import (
+	"runtime"
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestAbandonDetector(t *testing.T) {
+	var got []lockfree.Abandoned
+	d := lockfree.NewAbandonDetector(func(a lockfree.Abandoned) { got = append(got, a) })
+	d.IdleAfter = time.Minute
+
+	stuck, busy := lockfree.NewQueue(), lockfree.NewQueue()
+	d.Watch(stuck, "stuck")
+	d.Watch(busy, "busy")
+
+	now := time.Unix(0, 0)
+	for i := 0; i < 5; i++ {
+		stuck.Enqueue(i)
+		busy.Enqueue(i)
+		busy.Dequeue()
+		d.Observe(now)
+		now = now.Add(30 * time.Second)
+	}
+	if len(got) != 1 || got[0].Name != "stuck" || got[0].Depth != 3 || got[0].Unreachable {
+		t.Fatalf("unexpected warnings: %+v", got)
+	}
+
+	// 报警只触发一次；出现出队后重新计时
+	d.Observe(now.Add(time.Hour))
+	stuck.Dequeue()
+	d.Observe(now.Add(2 * time.Hour))
+	if len(got) != 1 {
+		t.Fatalf("warning repeated: %+v", got)
+	}
+
+	d.Unwatch(stuck)
+	stuck.Enqueue(1)
+	d.Observe(now.Add(3 * time.Hour))
+	d.Observe(now.Add(4 * time.Hour))
+	if len(got) != 1 {
+		t.Fatalf("unwatched queue reported: %+v", got)
+	}
+}
+
+func TestWarnIfCollected(t *testing.T) {
+	warned := make(chan lockfree.Abandoned, 1)
+	func() {
+		q := lockfree.NewQueue()
+		q.Enqueue(1)
+		lockfree.WarnIfCollected(q, "dropped", func(a lockfree.Abandoned) { warned <- a })
+	}()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		runtime.GC()
+		select {
+		case a := <-warned:
+			if a.Name != "dropped" || !a.Unreachable || a.Depth != 1 {
+				t.Fatalf("unexpected warning: %+v", a)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("finalizer did not report the non-empty queue")
+		}
+	}
+}
@@ -0,0 +1,77 @@
+//go:build !lockfreequeue_leakcheck
+
+package lockfreequeue_test
+
+import (
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+// 分配次数的回归门槛。race 检测器会随机丢弃 sync.Pool 中的对象，此时跳过。
+func checkAllocs(t *testing.T, name string, want float64, f func()) {
+	t.Helper()
+	if raceEnabled {
+		t.Skip("allocation counts are not stable under the race detector")
+	}
+	// 预热对象池
+	for i := 0; i < 100; i++ {
+		f()
+	}
+	if got := testing.AllocsPerRun(1000, f); got != want {
+		t.Errorf("%s: want %v allocs per op, got %v", name, want, got)
+	}
+}
+
+func TestAllocs(t *testing.T) {
+	q := lockfree.NewQueue()
+	p := new(int)
+	checkAllocs(t, "Queue pointer", 0, func() {
+		q.Enqueue(p)
+		q.Dequeue()
+	})
+
+	gq := lockfree.NewQueue(lockfree.WithReclamation(lockfree.ReclaimGC))
+	checkAllocs(t, "Queue ReclaimGC", 1, func() {
+		gq.Enqueue(p)
+		gq.Dequeue()
+	})
+
+	n := 1 << 20
+	checkAllocs(t, "Queue boxed int", 1, func() {
+		n++
+		q.Enqueue(n)
+		q.Dequeue()
+	})
+
+	checkAllocs(t, "Queue handle", 1, func() {
+		q.EnqueueHandle(p)
+		q.Dequeue()
+	})
+
+	qi := lockfree.NewQueueOf[int]()
+	checkAllocs(t, "QueueOf int", 1, func() {
+		n++
+		qi.Enqueue(n)
+		qi.Dequeue()
+	})
+
+	vs, buf := []any{p, p, p, p}, make([]any, 4)
+	checkAllocs(t, "Queue batch", 0, func() {
+		q.EnqueueBatch(vs)
+		q.DequeueBatchInto(buf)
+	})
+
+	rq := lockfree.NewBoundedQueue(16)
+	checkAllocs(t, "BoundedQueue pointer", 0, func() {
+		rq.TryEnqueue(p)
+		rq.TryDequeue()
+	})
+
+	bq := lockfree.NewByteQueue()
+	b := make([]byte, 16)
+	checkAllocs(t, "ByteQueue", 1, func() {
+		bq.Enqueue(b, nil)
+		bq.Dequeue().Release()
+	})
+}
@@ -0,0 +1,109 @@
+package lockfreequeue
+
+import (
+	"context"
+	"math"
+	"time"
+)
+
+// Anomaly 描述一次异常的队列增长。
+type Anomaly struct {
+	At    time.Time
+	Depth uint64
+	// Rate 是本次采样的增长速率（元素/秒）。
+	Rate float64
+	// Mean、StdDev 是此前学习到的增长速率的 EWMA 均值与标准差。
+	Mean   float64
+	StdDev float64
+}
+
+// AnomalyDetector 周期性采样队列长度，用 EWMA 学习长度增长速率的均值与方差，
+// 当某次增长速率高出均值 Threshold 个标准差时调用 OnAnomaly，
+// 比固定水位线更早发现消费者卡住的情况。
+type AnomalyDetector struct {
+	// Interval 是采样间隔，为 0 时使用 1 秒。
+	Interval time.Duration
+	// Alpha 是 EWMA 的平滑系数，取值 (0, 1]，为 0 时使用 0.1。
+	Alpha float64
+	// Threshold 是触发回调所需的标准差倍数，为 0 时使用 3。
+	Threshold float64
+	// MinStdDev 是标准差的下限（元素/秒），避免在非常平稳的负载下对微小波动报警。
+	MinStdDev float64
+	// Warmup 是开始检测之前用于学习的采样次数，为 0 时使用 10。
+	Warmup int
+	// OnAnomaly 在检测到异常时被调用。
+	OnAnomaly func(Anomaly)
+
+	q         *Queue
+	samples   int
+	lastDepth uint64
+	lastAt    time.Time
+	mean      float64
+	variance  float64
+}
+
+// NewAnomalyDetector 创建一个监视 q 的 AnomalyDetector。
+func NewAnomalyDetector(q *Queue, onAnomaly func(Anomaly)) *AnomalyDetector {
+	return &AnomalyDetector{q: q, OnAnomaly: onAnomaly}
+}
+
+// Run 按 Interval 持续采样，直到 ctx 结束。
+func (d *AnomalyDetector) Run(ctx context.Context) error {
+	interval := d.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctxErr(ctx)
+		case now := <-t.C:
+			d.Observe(now)
+		}
+	}
+}
+
+// Observe 在时刻 now 采样一次队列长度。Run 会自动调用它，也可以由调用方自行驱动。
+// Observe 不是并发安全的，同一时刻只能有一个调用者。
+func (d *AnomalyDetector) Observe(now time.Time) {
+	depth := d.q.Length()
+	if d.lastAt.IsZero() {
+		d.lastDepth, d.lastAt = depth, now
+		return
+	}
+	elapsed := now.Sub(d.lastAt).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+	rate := (float64(depth) - float64(d.lastDepth)) / elapsed
+	d.lastDepth, d.lastAt = depth, now
+
+	warmup, alpha, threshold := d.Warmup, d.Alpha, d.Threshold
+	if warmup <= 0 {
+		warmup = 10
+	}
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.1
+	}
+	if threshold <= 0 {
+		threshold = 3
+	}
+
+	if d.samples >= warmup && d.OnAnomaly != nil {
+		std := math.Max(math.Sqrt(d.variance), d.MinStdDev)
+		if rate > d.mean && rate-d.mean > threshold*std {
+			d.OnAnomaly(Anomaly{At: now, Depth: depth, Rate: rate, Mean: d.mean, StdDev: std})
+		}
+	}
+
+	if d.samples == 0 {
+		d.mean = rate
+	} else {
+		diff := rate - d.mean
+		d.mean += alpha * diff
+		d.variance = (1 - alpha) * (d.variance + alpha*diff*diff)
+	}
+	d.samples++
+}
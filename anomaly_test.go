@@ -0,0 +1,46 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestAnomalyDetector(t *testing.T) {
+	q := lockfree.NewQueue()
+	var fired []lockfree.Anomaly
+	d := lockfree.NewAnomalyDetector(q, func(a lockfree.Anomaly) { fired = append(fired, a) })
+	d.MinStdDev = 1
+
+	now := time.Unix(0, 0)
+	d.Observe(now)
+	// 平稳负载：长度在 0 和 2 之间波动
+	for i := 0; i < 30; i++ {
+		now = now.Add(time.Second)
+		if i%2 == 0 {
+			q.Enqueue(1)
+			q.Enqueue(2)
+		} else {
+			q.Dequeue()
+			q.Dequeue()
+		}
+		d.Observe(now)
+	}
+	if len(fired) != 0 {
+		t.Fatalf("steady load reported as anomaly: %+v", fired)
+	}
+
+	// 消费者卡住，长度突增
+	for i := 0; i < 100; i++ {
+		q.Enqueue(i)
+	}
+	now = now.Add(time.Second)
+	d.Observe(now)
+	if len(fired) != 1 {
+		t.Fatalf("want 1 anomaly, got %d", len(fired))
+	}
+	if fired[0].Depth != q.Length() {
+		t.Fatalf("anomaly depth %d, want %d", fired[0].Depth, q.Length())
+	}
+}
@@ -0,0 +1,192 @@
+package lockfreequeue
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// AuditQueue 包装一个队列，为每次入队与出队向 w 追加一行紧凑的审计记录：
+//
+//	<RFC3339Nano 时间戳> <enqueue|dequeue> <元素 ID>
+//
+// 元素 ID 由 id 函数给出；id 为 nil 时使用元素 %v 格式的 FNV-64a 哈希。
+// 写入失败不会影响队列操作，第一个写入错误可以通过 Err 获取。
+type AuditQueue struct {
+	q  *Queue
+	id func(v any) string
+
+	mu  sync.Mutex
+	w   io.Writer
+	buf []byte
+	err error
+}
+
+// NewAuditQueue 创建一个包装 q、将审计记录写入 w 的 AuditQueue。
+func NewAuditQueue(q *Queue, w io.Writer, id func(v any) string) *AuditQueue {
+	if id == nil {
+		id = hashItem
+	}
+	return &AuditQueue{q: q, w: w, id: id}
+}
+
+// Enqueue 记录审计日志并将 v 添加到队列末尾。
+// 入队记录先于入队写出，保证并发的出队记录不会排在它前面。
+func (a *AuditQueue) Enqueue(v any) {
+	a.log("enqueue", v)
+	a.q.Enqueue(v)
+}
+
+// Dequeue 从队列中移除并返回一个元素，并记录审计日志。队列为空时返回 nil 且不记录。
+func (a *AuditQueue) Dequeue() any {
+	v := a.q.Dequeue()
+	if v != nil {
+		a.log("dequeue", v)
+	}
+	return v
+}
+
+// Length returns the length of the underlying queue.
+func (a *AuditQueue) Length() uint64 {
+	return a.q.Length()
+}
+
+// Len returns the length of the underlying queue as an int.
+func (a *AuditQueue) Len() int {
+	return a.q.Len()
+}
+
+// Close 关闭底层队列。
+func (a *AuditQueue) Close() error {
+	return a.q.Close()
+}
+
+// Err 返回第一次写入审计记录时发生的错误。
+func (a *AuditQueue) Err() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+func (a *AuditQueue) log(op string, v any) {
+	id := a.id(v)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.buf = time.Now().UTC().AppendFormat(a.buf[:0], time.RFC3339Nano)
+	a.buf = append(a.buf, ' ')
+	a.buf = append(a.buf, op...)
+	a.buf = append(a.buf, ' ')
+	a.buf = append(a.buf, id...)
+	a.buf = append(a.buf, '\n')
+	if _, err := a.w.Write(a.buf); err != nil && a.err == nil {
+		a.err = err
+	}
+}
+
+func hashItem(v any) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%v", v)
+	return strconv.FormatUint(h.Sum64(), 16)
+}
+
+// RotatingFile 是一个按大小轮转的文件 io.WriteCloser。
+// 当前文件超过 maxBytes 时重命名为 path.1（已有的 path.N 依次后移），并重新创建 path；
+// 最多保留 backups 个历史文件。
+type RotatingFile struct {
+	path     string
+	maxBytes int64
+	backups  int
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+}
+
+// OpenRotatingFile 以追加模式打开 path 并返回一个 RotatingFile。
+func OpenRotatingFile(path string, maxBytes int64, backups int) (*RotatingFile, error) {
+	r := &RotatingFile{path: path, maxBytes: maxBytes, backups: backups}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write 实现 io.Writer，写入前如有需要先进行轮转。
+// 轮转失败时继续写入原文件，p 不会丢失，轮转错误随本次写入返回，下次写入时会再次尝试轮转。
+func (r *RotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rotateErr error
+	if r.maxBytes > 0 && r.size > 0 && r.size+int64(len(p)) > r.maxBytes {
+		rotateErr = r.rotate()
+		if r.f == nil {
+			return 0, rotateErr
+		}
+	}
+	n, err := r.f.Write(p)
+	r.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// Close 关闭当前文件。
+func (r *RotatingFile) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.f == nil {
+		return os.ErrClosed
+	}
+	err := r.f.Close()
+	r.f = nil
+	return err
+}
+
+func (r *RotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	r.f, r.size = f, fi.Size()
+	return nil
+}
+
+// rotate 关闭并轮转当前文件，然后重新打开 path。
+// 任何一步失败都会重新打开 path（可能仍是未轮转的原文件），只有重新打开也失败时 r.f 才为 nil。
+func (r *RotatingFile) rotate() error {
+	err := r.f.Close()
+	r.f = nil
+	if err == nil {
+		err = r.shift()
+	}
+	if openErr := r.open(); openErr != nil {
+		return errors.Join(err, openErr)
+	}
+	return err
+}
+
+// shift 将 path 重命名为 path.1，已有的历史文件依次后移；backups 为 0 时直接删除 path。
+func (r *RotatingFile) shift() error {
+	if r.backups <= 0 {
+		return os.Remove(r.path)
+	}
+	for i := r.backups - 1; i > 0; i-- {
+		os.Rename(r.backupName(i), r.backupName(i+1))
+	}
+	return os.Rename(r.path, r.backupName(1))
+}
+
+func (r *RotatingFile) backupName(i int) string {
+	return r.path + "." + strconv.Itoa(i)
+}
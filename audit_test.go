@@ -0,0 +1,87 @@
+package lockfreequeue_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestAuditQueue(t *testing.T) {
+	var buf bytes.Buffer
+	a := lockfree.NewAuditQueue(lockfree.NewQueue(), &buf, func(v any) string { return fmt.Sprint(v) })
+	a.Enqueue("job-1")
+	a.Dequeue()
+	a.Dequeue()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("want 2 records, got %d: %q", len(lines), buf.String())
+	}
+	for i, op := range []string{"enqueue", "dequeue"} {
+		fields := strings.Fields(lines[i])
+		if len(fields) != 3 || fields[1] != op || fields[2] != "job-1" {
+			t.Fatalf("unexpected record %q", lines[i])
+		}
+	}
+	if a.Err() != nil {
+		t.Fatalf("unexpected error: %v", a.Err())
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	f, err := lockfree.OpenRotatingFile(path, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		if _, err := f.Write([]byte("12345678\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Fatalf("missing %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Fatalf("backups beyond the limit must be discarded")
+	}
+}
+
+func TestRotatingFileRenameFailure(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	// path.1 是非空目录，轮转时的重命名必然失败
+	if err := os.MkdirAll(filepath.Join(path+".1", "x"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f, err := lockfree.OpenRotatingFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if _, err := f.Write([]byte("12345678\n")); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := f.Write([]byte("abcdefgh\n")); err == nil || n != 9 {
+		t.Fatalf("expected rotate error with the record still written, n=%d err=%v", n, err)
+	}
+	if _, err := f.Write([]byte("ijklmnop\n")); err == nil {
+		t.Fatalf("rotation should be retried and fail again")
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "12345678\nabcdefgh\nijklmnop\n" {
+		t.Fatalf("records lost after failed rotation: %q", b)
+	}
+}
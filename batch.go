@@ -0,0 +1,141 @@
+package lockfreequeue
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// AdaptiveBatcher 从队列中消费元素并按批交给 handler，批大小根据观测到的到达速率与处理延迟自动调整：
+// 流量大时合并更多元素（类似 Nagle 算法），流量小时每个元素立即交付。
+//
+// 队列中有积压时，连续取出的元素会被合并为一批，直到 MaxBatch。
+// 队列取空后，若已攒够目标批大小则立即交付，否则最多等待 MaxDelay 后交付已有元素；
+// 目标批大小约等于处理一批期间预计到达的元素个数，即 到达速率 × 处理延迟，范围为 [1, MaxBatch]。
+type AdaptiveBatcher struct {
+	// MaxBatch 是单批元素个数的上限。
+	MaxBatch int
+	// MaxDelay 是攒批的最长等待时间。
+	MaxDelay time.Duration
+	// Alpha 是速率与延迟 EWMA 的平滑系数，为 0 时使用 0.2。
+	Alpha float64
+
+	q       *Queue
+	handler func(batch []any)
+	target  int64
+	rate    float64 // 元素/秒
+	latency float64 // 秒/批
+}
+
+// NewAdaptiveBatcher 创建一个从 q 消费、交付给 handler 的 AdaptiveBatcher。
+// handler 收到的切片在其返回后会被复用，不能被保留。
+func NewAdaptiveBatcher(q *Queue, maxBatch int, maxDelay time.Duration, handler func(batch []any)) *AdaptiveBatcher {
+	if maxBatch < 1 {
+		maxBatch = 1
+	}
+	return &AdaptiveBatcher{
+		MaxBatch: maxBatch,
+		MaxDelay: maxDelay,
+		q:        q,
+		handler:  handler,
+		target:   1,
+	}
+}
+
+// BatchSize 返回当前的目标批大小。
+func (b *AdaptiveBatcher) BatchSize() int {
+	return int(atomic.LoadInt64(&b.target))
+}
+
+// Run 持续消费队列直到 ctx 结束，结束前交付已攒下的元素。
+func (b *AdaptiveBatcher) Run(ctx context.Context) error {
+	batch := make([]any, 0, b.MaxBatch)
+	last, lastDepth := time.Now(), b.q.Length()
+	var deadline time.Time
+	idle := pollInterval
+
+	flush := func() {
+		start := time.Now()
+		b.handler(batch)
+		end := time.Now()
+		// 到达数 = 本批取出的元素 + 期间积压的变化，积压增长说明到达速率高于当前的消费速率
+		depth := b.q.Length()
+		arrived := float64(len(batch)) + float64(depth) - float64(lastDepth)
+		b.observe(arrived, end.Sub(last), end.Sub(start))
+		batch, deadline, last, lastDepth = batch[:0], time.Time{}, end, depth
+	}
+
+	for {
+		if ctx.Err() != nil {
+			if len(batch) > 0 {
+				flush()
+			}
+			return ctxErr(ctx)
+		}
+		if v := b.q.Dequeue(); v != nil {
+			// 有积压时尽量合并，直到达到上限
+			idle = pollInterval
+			batch = append(batch, v)
+			if len(batch) >= b.MaxBatch {
+				flush()
+			}
+			continue
+		}
+		if len(batch) > 0 {
+			// 队列已空：达到目标批大小则立即交付，否则最多等待 MaxDelay 让后续元素到达
+			now := time.Now()
+			if deadline.IsZero() {
+				deadline = now.Add(b.MaxDelay)
+			}
+			if len(batch) >= b.BatchSize() || !now.Before(deadline) {
+				flush()
+				continue
+			}
+			// 正在攒批时保持较短的轮询间隔，以便及时交付
+			idle = pollInterval
+		}
+		sleep(ctx, idle)
+		// 持续空闲时逐步拉长轮询间隔，避免空转
+		if len(batch) == 0 {
+			idle = min(2*idle, maxIdleInterval)
+		}
+	}
+}
+
+// maxIdleInterval 是 AdaptiveBatcher 空闲轮询间隔的上限。
+const maxIdleInterval = 10 * time.Millisecond
+
+// sleep 休眠 d 或直到 ctx 结束。
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
+
+// observe 用上一批以来的到达数、经过的时间和处理耗时更新 EWMA 并重新计算目标批大小。
+func (b *AdaptiveBatcher) observe(arrived float64, elapsed, handle time.Duration) {
+	alpha := b.Alpha
+	if alpha <= 0 || alpha > 1 {
+		alpha = 0.2
+	}
+	if elapsed <= 0 {
+		return
+	}
+	if arrived < 0 {
+		arrived = 0
+	}
+	b.rate += alpha * (arrived/elapsed.Seconds() - b.rate)
+	b.latency += alpha * (handle.Seconds() - b.latency)
+
+	target := int64(b.rate*b.latency + 0.5)
+	if target < 1 {
+		target = 1
+	}
+	if target > int64(b.MaxBatch) {
+		target = int64(b.MaxBatch)
+	}
+	atomic.StoreInt64(&b.target, target)
+}
@@ -0,0 +1,80 @@
+package lockfreequeue_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestAdaptiveBatcher(t *testing.T) {
+	q := lockfree.NewQueue()
+	var (
+		mu      sync.Mutex
+		sizes   []int
+		handled int
+	)
+	b := lockfree.NewAdaptiveBatcher(q, 64, 5*time.Millisecond, func(batch []any) {
+		mu.Lock()
+		sizes = append(sizes, len(batch))
+		handled += len(batch)
+		mu.Unlock()
+		time.Sleep(time.Millisecond)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		b.Run(ctx)
+	}()
+
+	// 单个元素在轻负载下立即交付
+	q.Enqueue(0)
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return handled == 1
+	})
+	mu.Lock()
+	if sizes[0] != 1 {
+		t.Fatalf("light traffic should deliver immediately, got batch of %d", sizes[0])
+	}
+	mu.Unlock()
+
+	// 高负载下批大小增长
+	const total = 5000
+	for i := 0; i < total; i++ {
+		q.Enqueue(i)
+	}
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return handled == total+1
+	})
+	cancel()
+	<-done
+
+	max := 0
+	for _, n := range sizes {
+		if n > max {
+			max = n
+		}
+	}
+	if max <= 1 {
+		t.Fatalf("batch size never grew under load")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met before deadline")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,254 @@
+package lockfreequeue
+
+import (
+	"math/bits"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// cacheLineSize 是用于填充的缓存行大小，避免生产者与消费者频繁写入的字段落在同一缓存行上造成伪共享。
+const cacheLineSize = 64
+
+// ringSealed 是 boundedRing.enqPos 的最高位，置位后环形缓冲不再接受入队。
+const ringSealed = 1 << 63
+
+// boundedSlot 是环形缓冲中的一个槽位，seq 表示槽位当前可以被哪一个位置的写入或读取使用。
+type boundedSlot struct {
+	seq uint64
+	v   any
+	_   [cacheLineSize - 24]byte
+}
+
+// boundedRing 是 BoundedQueue 的一个环形缓冲，入队与出队位置以及每个槽位各自独占一个缓存行。
+type boundedRing struct {
+	_      [cacheLineSize]byte
+	enqPos uint64
+	_      [cacheLineSize - 8]byte
+	deqPos uint64
+	_      [cacheLineSize - 8]byte
+	mask   uint64
+	slots  []boundedSlot
+}
+
+// boundedRings 是 BoundedQueue 当前使用的环形缓冲，从旧到新排列。
+// 只有最后一个接受入队，前面的都已封存，取空后被移除。创建后不再修改，整体替换。
+type boundedRings struct {
+	rs []*boundedRing
+}
+
+// BoundedQueue 是容量有界的多生产者多消费者无锁队列，基于预先分配的环形缓冲，
+// 每个槽位带有序号（Dmitry Vyukov 的有界 MPMC 队列算法）。
+//
+// 与 Queue 相比，它在入队与出队时不分配也不复用节点，不存在节点复用带来的 ABA 问题；
+// 队列满时 TryEnqueue 立即失败，调用方可以据此拒绝请求或向上游施加背压。
+//
+// Resize 可以在运行中改变容量，不需要暂停生产者与消费者：新的入队进入新的环形缓冲，
+// 出队先取完旧缓冲中的元素再转向新缓冲，因此元素的先后顺序保持不变。
+type BoundedQueue struct {
+	rings  unsafe.Pointer // *boundedRings
+	closed int32
+	mu     sync.Mutex // 串行化 Resize
+}
+
+// NewBoundedQueue 创建一个至少能容纳 capacity 个元素的 BoundedQueue。
+// 容量会向上取整为 2 的幂，且至少为 2，实际容量由 Cap 返回。
+func NewBoundedQueue(capacity int) *BoundedQueue {
+	return &BoundedQueue{rings: unsafe.Pointer(&boundedRings{rs: []*boundedRing{newBoundedRing(capacity)}})}
+}
+
+func newBoundedRing(capacity int) *boundedRing {
+	n := uint64(2)
+	if capacity > 2 {
+		n = 1 << bits.Len64(uint64(capacity)-1)
+	}
+	r := &boundedRing{mask: n - 1, slots: make([]boundedSlot, n)}
+	for i := range r.slots {
+		r.slots[i].seq = uint64(i)
+	}
+	return r
+}
+
+func (q *BoundedQueue) load() *boundedRings {
+	return (*boundedRings)(atomic.LoadPointer(&q.rings))
+}
+
+// TryEnqueue 不阻塞地把 v 添加到队列末尾，队列已满或已关闭时返回 false。
+func (q *BoundedQueue) TryEnqueue(v any) bool {
+	return q.Offer(v) == nil
+}
+
+// Offer 与 TryEnqueue 相同，但用错误区分失败的原因。
+// 返回值:
+//
+//	error - 队列已满时返回 ErrFull，已关闭时返回 ErrClosed。
+func (q *BoundedQueue) Offer(v any) error {
+	if q.Closed() {
+		return ErrClosed
+	}
+	for {
+		st := q.load()
+		switch ok, sealed := st.rs[len(st.rs)-1].offer(v); {
+		case ok:
+			return nil
+		case !sealed:
+			return ErrFull
+		}
+		// 缓冲在此期间被 Resize 封存，新的缓冲已经发布，重新读取
+	}
+}
+
+// offer 尝试把 v 写入环形缓冲，缓冲已满时返回 false，已封存时 sealed 为 true。
+func (r *boundedRing) offer(v any) (ok, sealed bool) {
+	var b backoff
+	pos := atomic.LoadUint64(&r.enqPos)
+	for {
+		if pos&ringSealed != 0 {
+			return false, true
+		}
+		s := &r.slots[pos&r.mask]
+		seq := atomic.LoadUint64(&s.seq)
+		switch dif := int64(seq - pos); {
+		case dif == 0:
+			// 槽位空闲，抢占这个位置；封存会改变 enqPos，之后的 CAS 都会失败
+			if atomic.CompareAndSwapUint64(&r.enqPos, pos, pos+1) {
+				s.v = v
+				// 发布元素，消费者看到新的序号后才会读取 v
+				atomic.StoreUint64(&s.seq, pos+1)
+				return true, false
+			}
+		case dif < 0:
+			// 槽位仍保存着上一轮的元素，缓冲已满
+			return false, false
+		}
+		// 其他生产者抢先占用了这个位置
+		b.wait()
+		pos = atomic.LoadUint64(&r.enqPos)
+	}
+}
+
+// Enqueue 把 v 添加到队列末尾，队列已满时自旋或让出调度器直到有空间，用于实现 Interface。
+// 队列关闭后入队的元素会被丢弃。
+func (q *BoundedQueue) Enqueue(v any) {
+	var b backoff
+	for q.Offer(v) == ErrFull {
+		b.wait()
+	}
+}
+
+// TryDequeue 不阻塞地移除并返回队头的元素。队列为空时 ok 为 false。
+func (q *BoundedQueue) TryDequeue() (any, bool) {
+	for {
+		st := q.load()
+		r := st.rs[0]
+		if v, ok := r.dequeue(); ok {
+			return v, true
+		}
+		if len(st.rs) == 1 || !r.drained() {
+			// 最旧的缓冲中还有已占用但尚未写完的位置，为保持顺序不越过它
+			return nil, false
+		}
+		// 已封存的旧缓冲已经取空，把它移除后从下一个缓冲继续
+		atomic.CompareAndSwapPointer(&q.rings, unsafe.Pointer(st), unsafe.Pointer(&boundedRings{rs: st.rs[1:]}))
+	}
+}
+
+// dequeue 尝试从环形缓冲中取出一个元素，缓冲为空时返回 false。
+func (r *boundedRing) dequeue() (any, bool) {
+	var b backoff
+	pos := atomic.LoadUint64(&r.deqPos)
+	for {
+		s := &r.slots[pos&r.mask]
+		seq := atomic.LoadUint64(&s.seq)
+		switch dif := int64(seq - (pos + 1)); {
+		case dif == 0:
+			// 槽位中的元素已经发布，抢占这个位置
+			if atomic.CompareAndSwapUint64(&r.deqPos, pos, pos+1) {
+				v := s.v
+				s.v = nil
+				// 把槽位交还给下一轮的生产者
+				atomic.StoreUint64(&s.seq, pos+r.mask+1)
+				return v, true
+			}
+		case dif < 0:
+			// 槽位尚未写入，缓冲为空
+			return nil, false
+		}
+		b.wait()
+		pos = atomic.LoadUint64(&r.deqPos)
+	}
+}
+
+// drained 报告缓冲是否已封存且其中的元素都已取出，此时它不会再有任何元素。
+func (r *boundedRing) drained() bool {
+	e := atomic.LoadUint64(&r.enqPos)
+	return e&ringSealed != 0 && atomic.LoadUint64(&r.deqPos) == e&^ringSealed
+}
+
+// len 返回缓冲中的元素个数。
+func (r *boundedRing) len() uint64 {
+	d := atomic.LoadUint64(&r.deqPos)
+	e := atomic.LoadUint64(&r.enqPos) &^ ringSealed
+	if e <= d {
+		return 0
+	}
+	return min(e-d, r.mask+1)
+}
+
+// Dequeue 移除并返回队头的元素，队列为空时返回 nil，用于实现 Interface。
+func (q *BoundedQueue) Dequeue() any {
+	v, _ := q.TryDequeue()
+	return v
+}
+
+// Resize 把队列的容量改为至少 capacity（取整规则与 NewBoundedQueue 相同），生产者与消费者不需要暂停。
+// 之后的入队进入新容量的缓冲；调整前已入队的元素仍留在旧缓冲中，按顺序先被取出，取空后旧缓冲被释放。
+// 因此缩小容量时，在旧缓冲取空之前队列中的元素可能多于新的容量。
+func (q *BoundedQueue) Resize(capacity int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	r := newBoundedRing(capacity)
+	for {
+		st := q.load()
+		last := st.rs[len(st.rs)-1]
+		// 先发布新缓冲再封存旧缓冲，被封存挡住的生产者重新读取时一定能看到新缓冲
+		next := &boundedRings{rs: append(st.rs[:len(st.rs):len(st.rs)], r)}
+		if atomic.CompareAndSwapPointer(&q.rings, unsafe.Pointer(st), unsafe.Pointer(next)) {
+			for {
+				e := atomic.LoadUint64(&last.enqPos)
+				if atomic.CompareAndSwapUint64(&last.enqPos, e, e|ringSealed) {
+					return
+				}
+			}
+		}
+		// 消费者刚刚移除了一个取空的旧缓冲，重试
+	}
+}
+
+// Len 返回队列中的元素个数。并发修改时只是一个近似值。
+func (q *BoundedQueue) Len() int {
+	var n uint64
+	for _, r := range q.load().rs {
+		n += r.len()
+	}
+	return int(n)
+}
+
+// Cap 返回队列的容量，即最近一次 Resize（或 NewBoundedQueue）确定的容量。
+func (q *BoundedQueue) Cap() int {
+	st := q.load()
+	return int(st.rs[len(st.rs)-1].mask + 1)
+}
+
+// Close 将队列标记为已关闭，之后的入队都会失败，重复调用返回 ErrClosed。队列中已有的元素仍然可以出队。
+func (q *BoundedQueue) Close() error {
+	if !atomic.CompareAndSwapInt32(&q.closed, 0, 1) {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Closed 报告队列是否已被关闭。
+func (q *BoundedQueue) Closed() bool {
+	return atomic.LoadInt32(&q.closed) != 0
+}
@@ -0,0 +1,220 @@
+package lockfreequeue_test
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestBoundedQueue(t *testing.T) {
+	q := lockfree.NewBoundedQueue(3)
+	if q.Cap() != 4 {
+		t.Fatalf("want capacity rounded up to 4, got %d", q.Cap())
+	}
+	if _, ok := q.TryDequeue(); ok {
+		t.Fatal("empty queue: want ok=false")
+	}
+	// 多绕几圈，覆盖槽位序号的回绕
+	for round := 0; round < 3; round++ {
+		for i := 0; i < q.Cap(); i++ {
+			if !q.TryEnqueue(i) {
+				t.Fatalf("round %d: enqueue %d rejected", round, i)
+			}
+		}
+		if err := q.Offer(-1); !errors.Is(err, lockfree.ErrFull) {
+			t.Fatalf("full queue: want ErrFull, got %v", err)
+		}
+		if q.Len() != q.Cap() {
+			t.Fatalf("want len %d, got %d", q.Cap(), q.Len())
+		}
+		for i := 0; i < q.Cap(); i++ {
+			if v, ok := q.TryDequeue(); !ok || v != i {
+				t.Fatalf("round %d: want %d, got %v, %v", round, i, v, ok)
+			}
+		}
+	}
+
+	q.Enqueue(nil)
+	if v, ok := q.TryDequeue(); !ok || v != nil {
+		t.Fatalf("want queued nil, got %v, %v", v, ok)
+	}
+
+	q.Enqueue(1)
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Offer(2); !errors.Is(err, lockfree.ErrClosed) {
+		t.Fatalf("closed queue: want ErrClosed, got %v", err)
+	}
+	if v := q.Dequeue(); v != 1 {
+		t.Fatalf("want 1 after close, got %v", v)
+	}
+	if err := q.Close(); !errors.Is(err, lockfree.ErrClosed) {
+		t.Fatalf("second close: want ErrClosed, got %v", err)
+	}
+}
+
+func TestBoundedQueueConcurrent(t *testing.T) {
+	const producers, consumers, per = 4, 4, 10000
+	q := lockfree.NewBoundedQueue(64)
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < per; i++ {
+				q.Enqueue(p*per + i)
+			}
+		}(p)
+	}
+
+	var n int64
+	got := make([][]int, consumers)
+	var cwg sync.WaitGroup
+	for c := 0; c < consumers; c++ {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			for atomic.LoadInt64(&n) < producers*per {
+				v, ok := q.TryDequeue()
+				if !ok {
+					runtime.Gosched()
+					continue
+				}
+				got[c] = append(got[c], v.(int))
+				atomic.AddInt64(&n, 1)
+			}
+		}(c)
+	}
+	wg.Wait()
+	cwg.Wait()
+	seen := make([]bool, producers*per)
+	for _, vs := range got {
+		for _, v := range vs {
+			if seen[v] {
+				t.Fatalf("%d dequeued twice", v)
+			}
+			seen[v] = true
+		}
+	}
+	if q.Len() != 0 {
+		t.Fatalf("want empty queue, got len %d", q.Len())
+	}
+}
+
+func TestBoundedQueueResize(t *testing.T) {
+	q := lockfree.NewBoundedQueue(2)
+	q.Enqueue(0)
+	q.Enqueue(1)
+	q.Resize(8)
+	if q.Cap() != 8 {
+		t.Fatalf("want capacity 8 after grow, got %d", q.Cap())
+	}
+	for i := 2; i < 10; i++ {
+		if !q.TryEnqueue(i) {
+			t.Fatalf("grown queue: enqueue %d rejected", i)
+		}
+	}
+	if q.Len() != 10 {
+		t.Fatalf("want len 10 across both rings, got %d", q.Len())
+	}
+
+	// 缩小后旧缓冲中的元素仍按顺序先出队
+	q.Resize(2)
+	if q.Cap() != 2 {
+		t.Fatalf("want capacity 2 after shrink, got %d", q.Cap())
+	}
+	q.Enqueue(10)
+	q.Enqueue(11)
+	if err := q.Offer(12); !errors.Is(err, lockfree.ErrFull) {
+		t.Fatalf("shrunk queue: want ErrFull, got %v", err)
+	}
+	for i := 0; i < 12; i++ {
+		if v, ok := q.TryDequeue(); !ok || v != i {
+			t.Fatalf("want %d, got %v, %v", i, v, ok)
+		}
+	}
+	if _, ok := q.TryDequeue(); ok {
+		t.Fatal("drained queue: want ok=false")
+	}
+	if !q.TryEnqueue(12) || !q.TryEnqueue(13) {
+		t.Fatal("want room in the new ring after the old ones drain")
+	}
+}
+
+func TestBoundedQueueResizeConcurrent(t *testing.T) {
+	const producers, per = 4, 5000
+	q := lockfree.NewBoundedQueue(16)
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < per; i++ {
+				q.Enqueue(p*per + i)
+			}
+		}(p)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		for c := 0; ; c++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			q.Resize(4 << (c % 5))
+			runtime.Gosched()
+		}
+	}()
+
+	// 单个消费者检查每个生产者的元素保持先后顺序
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+	for n := 0; n < producers*per; {
+		v, ok := q.TryDequeue()
+		if !ok {
+			runtime.Gosched()
+			continue
+		}
+		p, i := v.(int)/per, v.(int)%per
+		if i <= last[p] {
+			t.Fatalf("producer %d: got %d after %d", p, i, last[p])
+		}
+		last[p] = i
+		n++
+	}
+	close(done)
+	wg.Wait()
+	for p, i := range last {
+		if i != per-1 {
+			t.Fatalf("producer %d: last item %d, want %d", p, i, per-1)
+		}
+	}
+	if q.Len() != 0 {
+		t.Fatalf("want empty queue, got len %d", q.Len())
+	}
+}
+
+func BenchmarkBoundedQueue(b *testing.B) {
+	queues := []lockfree.Interface{lockfree.NewQueue(), lockfree.NewBoundedQueue(1024)}
+	for _, q := range queues {
+		b.Run(fmt.Sprintf("%T", q), func(b *testing.B) {
+			v := new(int)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					q.Enqueue(v)
+					q.Dequeue()
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package lockfreequeue
+
+// DequeueBudget 从队头依次取出元素，直到它们的总大小再多一个就会超过 maxBytes，或者队列为空。
+// 元素的大小由 sizer 给出；为保证进度，第一个元素即使单独超过 maxBytes 也会被取出。
+// 超出预算的元素留在队头，不会被取出。通过 EnqueueHandle 入队的元素按其 Value 计算大小。
+// sizer 可能对同一个元素被调用多次，必须没有副作用。队列为空时返回 nil。
+func (q *Queue) DequeueBudget(maxBytes int, sizer func(any) int) []any {
+	var batch []any
+	used, size := 0, 0
+	fits := func(v any) bool {
+		if h, ok := v.(*Handle); ok {
+			v = h.v
+		}
+		size = sizer(v)
+		return len(batch) == 0 || used+size <= maxBytes
+	}
+	e := q.pin()
+	defer q.unpin(e)
+	for used < maxBytes || len(batch) == 0 {
+		v, freed := q.dequeueIf(fits)
+		if freed == nil {
+			break
+		}
+		q.free(freed)
+		if v, ok := unwrap(v); ok {
+			batch = append(batch, v)
+			used += size
+		}
+	}
+	return batch
+}
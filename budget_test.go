@@ -0,0 +1,32 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestDequeueBudget(t *testing.T) {
+	q := lockfree.NewQueue()
+	for _, s := range []string{"aaaa", "bbbb", "cc", "dddddddddd", "e"} {
+		q.Enqueue(s)
+	}
+	size := func(v any) int { return len(v.(string)) }
+
+	if batch := q.DequeueBudget(10, size); len(batch) != 3 || batch[2] != "cc" {
+		t.Fatalf("want the first three items, got %v", batch)
+	}
+	// 单个元素超过预算时也会被取出，保证进度
+	if batch := q.DequeueBudget(4, size); len(batch) != 1 || batch[0] != "dddddddddd" {
+		t.Fatalf("oversized head item must be returned alone, got %v", batch)
+	}
+	h := q.EnqueueHandle("ff")
+	h.Remove()
+	q.Enqueue("g")
+	if batch := q.DequeueBudget(10, size); len(batch) != 2 || batch[0] != "e" || batch[1] != "g" {
+		t.Fatalf("removed handles must be skipped, got %v", batch)
+	}
+	if batch := q.DequeueBudget(10, size); batch != nil || q.Length() != 0 {
+		t.Fatalf("empty queue must return nil, got %v", batch)
+	}
+}
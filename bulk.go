@@ -0,0 +1,124 @@
+package lockfreequeue
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// EnqueueBatch 把 vs 中的元素按顺序添加到队列末尾。
+// 元素先在本地链接成一条链，再用一次尾部 CAS 整体追加，因此这批元素在队列中是连续的，
+// 消费者要么一个都看不到，要么能看到全部。
+func (q *Queue) EnqueueBatch(vs []any) {
+	if len(vs) == 0 {
+		return
+	}
+	var first, last *directItem
+	for _, v := range vs {
+		i := q.newItem()
+		i.next, i.v = nil, v
+		if first == nil {
+			first = i
+		} else {
+			last.next = unsafe.Pointer(i)
+		}
+		last = i
+	}
+	q.enqueueChain(first, last, uint64(len(vs)))
+}
+
+// DequeueBatch 从队头移除并返回至多 max 个元素，队列为空时返回 nil。
+// 每次头部 CAS 可以摘下多个节点，比逐个 Dequeue 的竞争更少。
+func (q *Queue) DequeueBatch(max int) []any {
+	if max <= 0 {
+		return nil
+	}
+	var batch []any
+	for took := true; took && len(batch) < max; {
+		batch, took = q.dequeueChain(batch, max-len(batch))
+	}
+	return batch
+}
+
+// DequeueBatchInto 与 DequeueBatch 相同，但把元素写入 buf 而不分配新的切片。
+// 返回值:
+//
+//	int - 写入 buf 的元素个数，至多为 len(buf)。
+func (q *Queue) DequeueBatchInto(buf []any) int {
+	batch := buf[:0]
+	for took := true; took && len(batch) < len(buf); {
+		batch, took = q.dequeueChain(batch, len(buf)-len(batch))
+	}
+	return len(batch)
+}
+
+// Drain 用一次头部 CAS 摘下当前队列中的全部元素并按顺序返回，队列为空时返回 nil。
+// Drain 只取到操作开始时读到的队尾为止，与之并发入队的元素留在队列中。
+func (q *Queue) Drain() []any {
+	batch, _ := q.dequeueChain(nil, int(^uint(0)>>1))
+	return batch
+}
+
+// dequeueChain 用一次头部 CAS 从队头摘下至多 max 个节点，把其中的元素追加到 batch 并返回。
+// 为了保证头部不越过尾部，它最多摘到读取到的尾部节点为止；
+// 已被 Handle.Remove 删除的元素会被丢弃，因此追加的个数可能少于摘下的节点数。
+// 队列为空、没有摘下任何节点时 took 为 false。
+func (q *Queue) dequeueChain(batch []any, max int) (_ []any, took bool) {
+	start := len(batch)
+	var b backoff
+	e := q.pin()
+	defer q.unpin(e)
+	for {
+		first := loaditem(&q.head)
+		last := loaditem(&q.tail)
+		next := loaditem(&first.next)
+		if first == loaditem(&q.head) {
+			if first == last {
+				if next == nil {
+					// 丢弃之前 CAS 失败的尝试中读取的值
+					clear(batch[start:])
+					return batch[:start], false
+				}
+				// 尾部指针落后，尝试将其向前移动
+				casitem(&q.tail, last, next)
+			} else {
+				// 在交换头部指针之前读取各节点的值，end 成为新的头部节点
+				batch = batch[:start]
+				end := first
+				for n := 0; n < max; n++ {
+					next := loaditem(&end.next)
+					if next == nil {
+						break
+					}
+					end = next
+					batch = append(batch, end.v)
+					if end == last {
+						break
+					}
+				}
+				if casitem(&q.head, first, end) {
+					atomic.AddUint64(&q.dequeued, uint64(len(batch)-start))
+					// 回收 first 到 end 之前的旧节点，end 之前的节点此时只有本次调用能够访问其 next
+					for i := first; i != end; {
+						next := loaditem(&i.next)
+						q.free(i)
+						i = next
+					}
+					return unwrapAll(batch, start), true
+				}
+			}
+		}
+		b.wait()
+	}
+}
+
+// unwrapAll 对 batch[start:] 中的原始值调用 unwrap，原地移除已被删除的 Handle。
+func unwrapAll(batch []any, start int) []any {
+	out := batch[:start]
+	for _, v := range batch[start:] {
+		if v, ok := unwrap(v); ok {
+			out = append(out, v)
+		}
+	}
+	clear(batch[len(out):])
+	return out
+}
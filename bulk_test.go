@@ -0,0 +1,174 @@
+package lockfreequeue_test
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestBatch(t *testing.T) {
+	q := lockfree.NewQueue()
+	if got := q.DequeueBatch(4); got != nil {
+		t.Fatalf("empty queue: want nil, got %v", got)
+	}
+	q.EnqueueBatch([]any{1, 2, 3, 4, 5})
+	q.Enqueue(6)
+	if q.Len() != 6 {
+		t.Fatalf("want len 6, got %d", q.Len())
+	}
+	if got := q.DequeueBatch(4); !reflect.DeepEqual(got, []any{1, 2, 3, 4}) {
+		t.Fatalf("want [1 2 3 4], got %v", got)
+	}
+	buf := make([]any, 4)
+	if n := q.DequeueBatchInto(buf); n != 2 || buf[0] != 5 || buf[1] != 6 {
+		t.Fatalf("want [5 6], got %v", buf[:n])
+	}
+	if q.Len() != 0 {
+		t.Fatalf("want empty queue, got len %d", q.Len())
+	}
+
+	// 已删除的 Handle 被跳过，不占用批大小
+	q.Enqueue(1)
+	q.EnqueueHandle(2).Remove()
+	q.EnqueueHandle(3).Remove()
+	q.EnqueueBatch([]any{4, 5})
+	if got := q.DequeueBatch(2); !reflect.DeepEqual(got, []any{1, 4}) {
+		t.Fatalf("want [1 4], got %v", got)
+	}
+	if got := q.Drain(); !reflect.DeepEqual(got, []any{5}) {
+		t.Fatalf("want [5], got %v", got)
+	}
+	if got := q.Drain(); got != nil || q.Dequeue() != nil {
+		t.Fatalf("want drained queue, got %v", got)
+	}
+}
+
+func TestBatchStats(t *testing.T) {
+	q := lockfree.NewQueue()
+	check := func(step string, want lockfree.Stats) {
+		t.Helper()
+		if got := q.Stats(); got != want {
+			t.Fatalf("%s: want %+v, got %+v", step, want, got)
+		}
+		if q.Length() != want.Depth || q.Len() != int(want.Depth) {
+			t.Fatalf("%s: want length %d, got %d", step, want.Depth, q.Length())
+		}
+	}
+
+	// 批量入队与出队各自只更新一次计数器，结果与逐个操作相同
+	q.EnqueueBatch([]any{1, 2, 3, 4, 5})
+	check("EnqueueBatch", lockfree.Stats{Depth: 5, Enqueued: 5})
+	p := q.NewProducer(3, time.Hour)
+	for i := 0; i < 4; i++ {
+		p.Enqueue(i)
+	}
+	p.Flush()
+	check("Producer", lockfree.Stats{Depth: 9, Enqueued: 9})
+	q.DequeueBatch(4)
+	check("DequeueBatch", lockfree.Stats{Depth: 5, Enqueued: 9, Dequeued: 4})
+	buf := make([]any, 2)
+	q.DequeueBatchInto(buf)
+	check("DequeueBatchInto", lockfree.Stats{Depth: 3, Enqueued: 9, Dequeued: 6})
+	q.Drain()
+	check("Drain", lockfree.Stats{Enqueued: 9, Dequeued: 9})
+	q.DequeueBatch(4)
+	check("empty DequeueBatch", lockfree.Stats{Enqueued: 9, Dequeued: 9})
+	p.Close()
+}
+
+func TestBatchConcurrent(t *testing.T) {
+	const producers, batches, size = 4, 500, 8
+	const total = producers * batches * size
+	q := lockfree.NewQueue()
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			vs := make([]any, size)
+			for b := 0; b < batches; b++ {
+				for i := range vs {
+					vs[i] = (p*batches+b)*size + i
+				}
+				q.EnqueueBatch(vs)
+			}
+		}(p)
+	}
+
+	var n int64
+	got := make([][]any, 3)
+	var cwg sync.WaitGroup
+	for c := range got {
+		cwg.Add(1)
+		go func(c int) {
+			defer cwg.Done()
+			buf := make([]any, 5)
+			for atomic.LoadInt64(&n) < total {
+				var vs []any
+				switch c {
+				case 0:
+					vs = q.DequeueBatch(3)
+				case 1:
+					vs = buf[:q.DequeueBatchInto(buf)]
+				default:
+					vs = q.Drain()
+				}
+				if len(vs) == 0 {
+					runtime.Gosched()
+					continue
+				}
+				got[c] = append(got[c], vs...)
+				atomic.AddInt64(&n, int64(len(vs)))
+			}
+		}(c)
+	}
+	wg.Wait()
+	cwg.Wait()
+
+	seen := make([]bool, total)
+	for _, vs := range got {
+		last := make(map[int]int)
+		for _, v := range vs {
+			v := v.(int)
+			if seen[v] {
+				t.Fatalf("%d dequeued twice", v)
+			}
+			seen[v] = true
+			// 同一生产者的元素对每个消费者保持入队顺序
+			p := v / (batches * size)
+			if prev, ok := last[p]; ok && v <= prev {
+				t.Fatalf("producer %d: %d after %d", p, v, prev)
+			}
+			last[p] = v
+		}
+	}
+	if q.Len() != 0 {
+		t.Fatalf("want empty queue, got len %d", q.Len())
+	}
+}
+
+func BenchmarkBatch(b *testing.B) {
+	for _, size := range []int{1, 16, 256} {
+		b.Run(fmt.Sprintf("size=%d", size), func(b *testing.B) {
+			q := lockfree.NewQueue()
+			v := new(int)
+			b.RunParallel(func(pb *testing.PB) {
+				vs := make([]any, size)
+				for i := range vs {
+					vs[i] = v
+				}
+				buf := make([]any, size)
+				for pb.Next() {
+					q.EnqueueBatch(vs)
+					q.DequeueBatchInto(buf)
+				}
+			})
+		})
+	}
+}
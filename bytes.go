@@ -0,0 +1,52 @@
+package lockfreequeue
+
+import "sync/atomic"
+
+// Buffer 是在 ByteQueue 中传递的字节切片及其归还回调。
+// 入队后切片的所有权转移给队列，出队后转移给消费者，消费者用完后调用 Release 归还。
+type Buffer struct {
+	B []byte
+
+	release  func([]byte)
+	released int32
+}
+
+// Release 将切片归还给入队时提供的回调，多次调用只生效一次。
+// 调用 Release 之后不能再访问 B。
+func (b *Buffer) Release() {
+	if !atomic.CompareAndSwapInt32(&b.released, 0, 1) {
+		return
+	}
+	if b.release != nil {
+		b.release(b.B)
+	}
+	b.B = nil
+}
+
+// ByteQueue 是传递字节切片所有权的队列，切片在生产者与消费者之间零拷贝地转移，
+// 消费者处理完成后通过 Release 把切片交还给调用方管理的缓冲池。
+type ByteQueue struct {
+	q *Queue
+}
+
+// NewByteQueue 创建并返回一个新的 ByteQueue 实例。
+func NewByteQueue() *ByteQueue {
+	return &ByteQueue{q: NewQueue()}
+}
+
+// Enqueue 将 b 的所有权转移给队列。release 在消费者调用 Buffer.Release 时被调用，可以为 nil。
+// 调用返回后生产者不能再访问 b。
+func (q *ByteQueue) Enqueue(b []byte, release func([]byte)) {
+	q.q.Enqueue(&Buffer{B: b, release: release})
+}
+
+// Dequeue 取出一个 Buffer，调用方获得其所有权并负责调用 Release。队列为空时返回 nil。
+func (q *ByteQueue) Dequeue() *Buffer {
+	b, _ := q.q.Dequeue().(*Buffer)
+	return b
+}
+
+// Length returns the number of queued buffers.
+func (q *ByteQueue) Length() uint64 {
+	return q.q.Length()
+}
@@ -0,0 +1,37 @@
+package lockfreequeue_test
+
+import (
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestByteQueue(t *testing.T) {
+	pool := sync.Pool{New: func() any { return make([]byte, 0, 64) }}
+	var released int
+	release := func(b []byte) {
+		released++
+		pool.Put(b[:0])
+	}
+
+	q := lockfree.NewByteQueue()
+	b := append(pool.Get().([]byte), "payload"...)
+	q.Enqueue(b, release)
+
+	buf := q.Dequeue()
+	if buf == nil || string(buf.B) != "payload" {
+		t.Fatalf("unexpected buffer %v", buf)
+	}
+	if &buf.B[0] != &b[0] {
+		t.Fatalf("buffer was copied")
+	}
+	buf.Release()
+	buf.Release()
+	if released != 1 {
+		t.Fatalf("release called %d times, want 1", released)
+	}
+	if q.Dequeue() != nil {
+		t.Fatalf("dequeue empty queue returns non-nil")
+	}
+}
@@ -0,0 +1,316 @@
+// lfqbench 在不同的 GOMAXPROCS 与生产者:消费者比例下测量各队列实现的吞吐量，
+// 输出 CSV 或 JSON 报告，并在标准错误上打印 ASCII 扩展性图表。
+//
+// 用法:
+//
+//	go run ./cmd/lfqbench -format csv -o report.csv
+//
+// 在 Linux 上加 -perf 参数时，还会为每次运行记录每个操作的硬件计数（周期、指令、缓存未命中、分支预测失败）。
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+	"github.com/hawkli-1994/lockfreequeue/internal/perf"
+)
+
+type mutexQueue struct {
+	mu sync.Mutex
+	v  []any
+}
+
+func (q *mutexQueue) Enqueue(v any) {
+	q.mu.Lock()
+	q.v = append(q.v, v)
+	q.mu.Unlock()
+}
+
+func (q *mutexQueue) Dequeue() any {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.v) == 0 {
+		return nil
+	}
+	v := q.v[0]
+	q.v[0] = nil
+	q.v = q.v[1:]
+	return v
+}
+
+func (q *mutexQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.v)
+}
+
+func (q *mutexQueue) Close() error { return nil }
+
+// variants 列出参与比较的队列实现。
+var variants = []struct {
+	name string
+	new  func() lockfree.Interface
+}{
+	{"lockfree.Queue", func() lockfree.Interface { return lockfree.NewQueue() }},
+	{"lockfree.Queue/pool", func() lockfree.Interface {
+		return lockfree.NewQueue(lockfree.WithReclamation(lockfree.ReclaimPool))
+	}},
+	{"lockfree.Queue/gc", func() lockfree.Interface {
+		return lockfree.NewQueue(lockfree.WithReclamation(lockfree.ReclaimGC))
+	}},
+	{"mutexQueue", func() lockfree.Interface { return &mutexQueue{} }},
+	{"chan", func() lockfree.Interface { return lockfree.NewChanQueue(1024) }},
+	{"lockfree.BoundedQueue", func() lockfree.Interface { return lockfree.NewBoundedQueue(1024) }},
+}
+
+// Result 是矩阵中一个单元格的测量结果。
+type Result struct {
+	Variant    string  `json:"variant"`
+	GOMAXPROCS int     `json:"gomaxprocs"`
+	Producers  int     `json:"producers"`
+	Consumers  int     `json:"consumers"`
+	Ops        int     `json:"ops"`
+	NsPerOp    float64 `json:"ns_per_op"`
+	OpsPerSec  float64 `json:"ops_per_sec"`
+	// PerOp 是每个操作的硬件事件计数，只在开启 -perf 时存在。
+	PerOp map[string]float64 `json:"per_op,omitempty"`
+}
+
+func main() {
+	var (
+		ops    = flag.Int("ops", 1<<20, "number of items moved through the queue per run")
+		procs  = flag.String("procs", "", "comma-separated GOMAXPROCS values (default powers of two up to NumCPU)")
+		ratios = flag.String("ratios", "1:1,1:4,4:1", "comma-separated producer:consumer ratios")
+		format = flag.String("format", "csv", "report format: csv or json")
+		out    = flag.String("o", "", "report file (default stdout)")
+		usePMU = flag.Bool("perf", false, "record hardware performance counters (Linux only)")
+	)
+	flag.Parse()
+
+	// 计数器需要在启动基准 goroutine 之前打开，之后创建的线程才会被统计
+	var counters *perf.Counters
+	if *usePMU {
+		c, err := perf.Open()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "lfqbench: perf counters unavailable:", err)
+		} else {
+			counters = c
+			defer counters.Close()
+		}
+	}
+
+	procList, err := parseProcs(*procs)
+	if err != nil {
+		fatal(err)
+	}
+	ratioList, err := parseRatios(*ratios)
+	if err != nil {
+		fatal(err)
+	}
+
+	var results []Result
+	for _, v := range variants {
+		for _, r := range ratioList {
+			for _, p := range procList {
+				runtime.GOMAXPROCS(p)
+				res := measure(counters, v.new(), r[0]*p, r[1]*p, *ops)
+				res.Variant, res.GOMAXPROCS = v.name, p
+				results = append(results, res)
+				fmt.Fprintf(os.Stderr, "%-16s procs=%-3d %d:%d %10.1f ns/op\n", v.name, p, r[0], r[1], res.NsPerOp)
+			}
+		}
+	}
+
+	w := io.Writer(os.Stdout)
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeReport(w, *format, results); err != nil {
+		fatal(err)
+	}
+	chart(os.Stderr, results)
+}
+
+// measure 运行一次 run，counters 不为 nil 时同时记录每个操作的硬件事件计数。
+func measure(counters *perf.Counters, q lockfree.Interface, producers, consumers, ops int) Result {
+	if counters == nil {
+		return run(q, producers, consumers, ops)
+	}
+	if err := counters.Start(); err != nil {
+		fatal(err)
+	}
+	res := run(q, producers, consumers, ops)
+	counts, err := counters.Stop()
+	if err != nil {
+		fatal(err)
+	}
+	res.PerOp = make(map[string]float64, len(counts))
+	for e, n := range counts {
+		res.PerOp[e.String()] = float64(n) / float64(ops)
+	}
+	return res
+}
+
+// run 用 producers 个生产者与 consumers 个消费者在 q 中传递 ops 个元素，返回耗时统计。
+func run(q lockfree.Interface, producers, consumers, ops int) Result {
+	var (
+		wg       sync.WaitGroup
+		consumed int64
+		item     = new(int)
+	)
+	start := time.Now()
+	for i := 0; i < producers; i++ {
+		n := ops / producers
+		if i < ops%producers {
+			n++
+		}
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			for j := 0; j < n; j++ {
+				q.Enqueue(item)
+			}
+		}(n)
+	}
+	for i := 0; i < consumers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt64(&consumed) < int64(ops) {
+				if q.Dequeue() != nil {
+					atomic.AddInt64(&consumed, 1)
+				} else {
+					runtime.Gosched()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	return Result{
+		Producers: producers,
+		Consumers: consumers,
+		Ops:       ops,
+		NsPerOp:   float64(elapsed.Nanoseconds()) / float64(ops),
+		OpsPerSec: float64(ops) / elapsed.Seconds(),
+	}
+}
+
+func writeReport(w io.Writer, format string, results []Result) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	case "csv":
+		cw := csv.NewWriter(w)
+		header := []string{"variant", "gomaxprocs", "producers", "consumers", "ops", "ns_per_op", "ops_per_sec"}
+		var events []string
+		if len(results) > 0 && results[0].PerOp != nil {
+			for _, e := range perf.DefaultEvents {
+				events = append(events, e.String())
+				header = append(header, e.String()+"_per_op")
+			}
+		}
+		cw.Write(header)
+		for _, r := range results {
+			row := []string{
+				r.Variant,
+				strconv.Itoa(r.GOMAXPROCS),
+				strconv.Itoa(r.Producers),
+				strconv.Itoa(r.Consumers),
+				strconv.Itoa(r.Ops),
+				strconv.FormatFloat(r.NsPerOp, 'f', 2, 64),
+				strconv.FormatFloat(r.OpsPerSec, 'f', 0, 64),
+			}
+			for _, e := range events {
+				row = append(row, strconv.FormatFloat(r.PerOp[e], 'f', 3, 64))
+			}
+			cw.Write(row)
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+	return fmt.Errorf("unknown format %q", format)
+}
+
+// chart 为每个实现与比例打印一组横向柱状图，柱长与吞吐量成正比。
+func chart(w io.Writer, results []Result) {
+	const width = 50
+	max := 0.0
+	for _, r := range results {
+		if r.OpsPerSec > max {
+			max = r.OpsPerSec
+		}
+	}
+	if max == 0 {
+		return
+	}
+	group := ""
+	for _, r := range results {
+		g := fmt.Sprintf("%s %d:%d", r.Variant, r.Producers/r.GOMAXPROCS, r.Consumers/r.GOMAXPROCS)
+		if g != group {
+			group = g
+			fmt.Fprintf(w, "\n%s (ops/sec)\n", g)
+		}
+		bar := int(r.OpsPerSec / max * width)
+		fmt.Fprintf(w, "  procs=%-3d |%-*s| %.2fM\n", r.GOMAXPROCS, width, strings.Repeat("#", bar), r.OpsPerSec/1e6)
+	}
+}
+
+func parseProcs(s string) ([]int, error) {
+	if s == "" {
+		var procs []int
+		for p := 1; p < runtime.NumCPU(); p *= 2 {
+			procs = append(procs, p)
+		}
+		return append(procs, runtime.NumCPU()), nil
+	}
+	var procs []int
+	for _, f := range strings.Split(s, ",") {
+		p, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || p < 1 {
+			return nil, fmt.Errorf("invalid GOMAXPROCS value %q", f)
+		}
+		procs = append(procs, p)
+	}
+	return procs, nil
+}
+
+func parseRatios(s string) ([][2]int, error) {
+	var ratios [][2]int
+	for _, f := range strings.Split(s, ",") {
+		pc := strings.Split(strings.TrimSpace(f), ":")
+		if len(pc) != 2 {
+			return nil, fmt.Errorf("invalid ratio %q", f)
+		}
+		p, err1 := strconv.Atoi(pc[0])
+		c, err2 := strconv.Atoi(pc[1])
+		if err1 != nil || err2 != nil || p < 1 || c < 1 {
+			return nil, fmt.Errorf("invalid ratio %q", f)
+		}
+		ratios = append(ratios, [2]int{p, c})
+	}
+	return ratios, nil
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, "lfqbench:", err)
+	os.Exit(1)
+}
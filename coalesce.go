@@ -0,0 +1,106 @@
+package lockfreequeue
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Call 代表一次被合并的任务，同一个键上的所有调用者共享同一个 Call。
+type Call[K comparable] struct {
+	key  K
+	fn   func() (any, error)
+	done chan struct{}
+	val  any
+	err  error
+}
+
+// Key 返回任务的键。
+func (c *Call[K]) Key() K {
+	return c.key
+}
+
+// Done 返回一个在任务执行完成后关闭的 channel。
+func (c *Call[K]) Done() <-chan struct{} {
+	return c.done
+}
+
+// Wait 等待任务执行完成并返回其结果；ctx 先结束时返回 ErrCancelled 或 ErrTimeout。
+func (c *Call[K]) Wait(ctx context.Context) (any, error) {
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctxErr(ctx)
+	}
+}
+
+// Coalescer 将队列与请求合并（singleflight）结合：
+// 若某个键已在排队或正在执行，再次入队不会产生新任务，而是返回同一个 Call，
+// 任务执行一次后结果广播给所有等待者。
+type Coalescer[K comparable] struct {
+	q       *Queue
+	mu      sync.Mutex
+	pending map[K]*Call[K]
+}
+
+// NewCoalescer 创建并返回一个新的 Coalescer 实例。
+func NewCoalescer[K comparable]() *Coalescer[K] {
+	return &Coalescer[K]{
+		q:       NewQueue(),
+		pending: make(map[K]*Call[K]),
+	}
+}
+
+// Enqueue 为 key 入队一个任务 fn。
+// 返回值:
+//
+//	*Call[K] - 与该键关联的任务句柄。
+//	bool     - 为 true 表示复用了已存在的任务，fn 不会被执行。
+func (c *Coalescer[K]) Enqueue(key K, fn func() (any, error)) (*Call[K], bool) {
+	c.mu.Lock()
+	if call, ok := c.pending[key]; ok {
+		c.mu.Unlock()
+		return call, true
+	}
+	call := &Call[K]{key: key, fn: fn, done: make(chan struct{})}
+	c.pending[key] = call
+	c.mu.Unlock()
+
+	c.q.Enqueue(call)
+	return call, false
+}
+
+// Process 取出一个任务并在当前 goroutine 中执行它。队列为空时返回 false。
+// 若任务 panic，等待者会收到描述该 panic 的错误，panic 随后继续向上传播。
+func (c *Coalescer[K]) Process() bool {
+	v := c.q.Dequeue()
+	if v == nil {
+		return false
+	}
+	call := v.(*Call[K])
+	defer func() {
+		if r := recover(); r != nil {
+			call.err = fmt.Errorf("lockfreequeue: coalesced call panicked: %v", r)
+			c.finish(call)
+			panic(r)
+		}
+		c.finish(call)
+	}()
+	call.val, call.err = call.fn()
+	return true
+}
+
+// finish 把 call 移出 pending 并广播结果。
+func (c *Coalescer[K]) finish(call *Call[K]) {
+	// 先移出 pending 再广播，之后同键的入队会产生新的任务
+	c.mu.Lock()
+	delete(c.pending, call.key)
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// Length 返回排队中（尚未被 Process 取出）的任务数。
+func (c *Coalescer[K]) Length() uint64 {
+	return c.q.Length()
+}
@@ -0,0 +1,71 @@
+package lockfreequeue_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestCoalescer(t *testing.T) {
+	c := lockfree.NewCoalescer[string]()
+	var runs int32
+	fn := func() (any, error) {
+		atomic.AddInt32(&runs, 1)
+		return "result", nil
+	}
+
+	first, shared := c.Enqueue("k", fn)
+	if shared {
+		t.Fatalf("first enqueue must not be shared")
+	}
+	second, shared := c.Enqueue("k", fn)
+	if !shared || second != first {
+		t.Fatalf("duplicate key must return the pending call")
+	}
+	if c.Length() != 1 {
+		t.Fatalf("duplicate key must not be queued, length %d", c.Length())
+	}
+
+	if !c.Process() {
+		t.Fatalf("process returned false on non-empty queue")
+	}
+	if c.Process() {
+		t.Fatalf("process returned true on empty queue")
+	}
+	for _, call := range []*lockfree.Call[string]{first, second} {
+		v, err := call.Wait(context.Background())
+		if v != "result" || err != nil {
+			t.Fatalf("unexpected result %v, %v", v, err)
+		}
+	}
+	if runs != 1 {
+		t.Fatalf("fn ran %d times, want 1", runs)
+	}
+
+	if _, shared := c.Enqueue("k", fn); shared {
+		t.Fatalf("enqueue after completion must start a new call")
+	}
+}
+
+func TestCoalescerPanic(t *testing.T) {
+	c := lockfree.NewCoalescer[string]()
+	call, _ := c.Enqueue("k", func() (any, error) { panic("boom") })
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Fatalf("panic must propagate, got %v", r)
+			}
+		}()
+		c.Process()
+	}()
+
+	if _, err := call.Wait(context.Background()); err == nil {
+		t.Fatalf("waiters must see the panic as an error")
+	}
+	if _, shared := c.Enqueue("k", func() (any, error) { return nil, nil }); shared {
+		t.Fatalf("key must be released after a panic")
+	}
+}
@@ -12,6 +12,8 @@ type Less func(a, b interface{}) bool
 type directItem struct {
 	next unsafe.Pointer
 	v    interface{}
+	// retired 在节点等待 epoch 回收期间链接 limbo 中的下一个节点
+	retired unsafe.Pointer
 }
 
 func loaditem(p *unsafe.Pointer) *directItem {
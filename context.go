@@ -0,0 +1,17 @@
+package lockfreequeue
+
+import "context"
+
+// queueKey 是队列在 context 中的键，未导出以避免与其他包冲突。
+type queueKey struct{}
+
+// NewContext 返回一个携带队列 q 的新 context，供调用链下游通过 FromContext 取出。
+func NewContext(ctx context.Context, q *Queue) context.Context {
+	return context.WithValue(ctx, queueKey{}, q)
+}
+
+// FromContext 返回 ctx 中携带的队列；若不存在则返回 nil, false。
+func FromContext(ctx context.Context) (*Queue, bool) {
+	q, ok := ctx.Value(queueKey{}).(*Queue)
+	return q, ok
+}
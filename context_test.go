@@ -0,0 +1,21 @@
+package lockfreequeue_test
+
+import (
+	"context"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestContext(t *testing.T) {
+	if _, ok := lockfree.FromContext(context.Background()); ok {
+		t.Fatalf("empty context carries a queue")
+	}
+
+	q := lockfree.NewQueue()
+	ctx := lockfree.NewContext(context.Background(), q)
+	got, ok := lockfree.FromContext(ctx)
+	if !ok || got != q {
+		t.Fatalf("queue not found in context")
+	}
+}
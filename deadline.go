@@ -0,0 +1,124 @@
+package lockfreequeue
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+)
+
+// ExpiredPolicy 决定 DeadlineQueue 如何处理出队时已过截止时间的元素。
+type ExpiredPolicy int
+
+const (
+	// DeliverExpired 照常返回已过期的元素，由消费者自行决定如何处理。
+	DeliverExpired ExpiredPolicy = iota
+	// DropExpired 丢弃已过期的元素（如设置了 OnExpired 则交给它），继续返回下一个元素。
+	DropExpired
+)
+
+type deadlineItem struct {
+	v        any
+	deadline time.Time
+	seq      uint64
+}
+
+type deadlineHeap []deadlineItem
+
+func (h deadlineHeap) Len() int { return len(h) }
+func (h deadlineHeap) Less(i, j int) bool {
+	if !h[i].deadline.Equal(h[j].deadline) {
+		return h[i].deadline.Before(h[j].deadline)
+	}
+	return h[i].seq < h[j].seq
+}
+func (h deadlineHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *deadlineHeap) Push(x any)   { *h = append(*h, x.(deadlineItem)) }
+func (h *deadlineHeap) Pop() any {
+	old := *h
+	it := old[len(old)-1]
+	old[len(old)-1] = deadlineItem{}
+	*h = old[:len(old)-1]
+	return it
+}
+
+// DeadlineQueue 是按截止时间最早优先（EDF）出队的队列，截止时间相同的元素保持入队顺序。
+// 内部使用互斥锁保护的二叉堆，入队与出队的复杂度为 O(log n)。
+type DeadlineQueue struct {
+	// OnExpired 在 DropExpired 策略下对每个被丢弃的元素调用，可以为 nil。
+	OnExpired func(v any, deadline time.Time)
+
+	policy ExpiredPolicy
+	mu     sync.Mutex
+	h      deadlineHeap
+	seq    uint64
+}
+
+// NewDeadlineQueue 创建并返回一个使用 policy 处理过期元素的 DeadlineQueue。
+func NewDeadlineQueue(policy ExpiredPolicy) *DeadlineQueue {
+	return &DeadlineQueue{policy: policy}
+}
+
+// Enqueue 添加一个截止时间为 deadline 的元素。
+func (q *DeadlineQueue) Enqueue(v any, deadline time.Time) {
+	q.mu.Lock()
+	q.seq++
+	heap.Push(&q.h, deadlineItem{v: v, deadline: deadline, seq: q.seq})
+	q.mu.Unlock()
+}
+
+// Dequeue 移除并返回截止时间最早的元素。队列为空时 ok 为 false。
+func (q *DeadlineQueue) Dequeue() (v any, deadline time.Time, ok bool) {
+	q.mu.Lock()
+	expired := q.dropExpired()
+	if len(q.h) > 0 {
+		it := heap.Pop(&q.h).(deadlineItem)
+		v, deadline, ok = it.v, it.deadline, true
+	}
+	q.mu.Unlock()
+
+	q.expire(expired)
+	return v, deadline, ok
+}
+
+// Peek 返回截止时间最早的元素但不移除它，即下一次 Dequeue 将返回的元素。队列为空时 ok 为 false。
+// DropExpired 策略下，位于队头的过期元素会像 Dequeue 时一样被丢弃。
+func (q *DeadlineQueue) Peek() (v any, deadline time.Time, ok bool) {
+	q.mu.Lock()
+	expired := q.dropExpired()
+	if len(q.h) > 0 {
+		v, deadline, ok = q.h[0].v, q.h[0].deadline, true
+	}
+	q.mu.Unlock()
+
+	q.expire(expired)
+	return v, deadline, ok
+}
+
+// dropExpired 在 DropExpired 策略下移除堆顶所有已过期的元素并返回它们，调用方必须持有 mu。
+func (q *DeadlineQueue) dropExpired() []deadlineItem {
+	if q.policy != DropExpired {
+		return nil
+	}
+	var expired []deadlineItem
+	now := time.Now()
+	for len(q.h) > 0 && q.h[0].deadline.Before(now) {
+		expired = append(expired, heap.Pop(&q.h).(deadlineItem))
+	}
+	return expired
+}
+
+// expire 在释放锁之后把被丢弃的元素交给 OnExpired。
+func (q *DeadlineQueue) expire(expired []deadlineItem) {
+	if q.OnExpired != nil {
+		for _, it := range expired {
+			q.OnExpired(it.v, it.deadline)
+		}
+	}
+}
+
+// Length returns the number of queued items, including expired ones not yet dropped.
+func (q *DeadlineQueue) Length() uint64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return uint64(len(q.h))
+}
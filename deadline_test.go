@@ -0,0 +1,78 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestDeadlineQueueOrder(t *testing.T) {
+	q := lockfree.NewDeadlineQueue(lockfree.DeliverExpired)
+	now := time.Now()
+	q.Enqueue("late", now.Add(3*time.Second))
+	q.Enqueue("soon-1", now.Add(time.Second))
+	q.Enqueue("soon-2", now.Add(time.Second))
+	q.Enqueue("past", now.Add(-time.Second))
+
+	for _, want := range []string{"past", "soon-1", "soon-2", "late"} {
+		v, _, ok := q.Dequeue()
+		if !ok || v != want {
+			t.Fatalf("want %s, got %v", want, v)
+		}
+	}
+	if _, _, ok := q.Dequeue(); ok {
+		t.Fatalf("dequeue empty queue returns ok")
+	}
+}
+
+func TestDeadlineQueueDropExpired(t *testing.T) {
+	q := lockfree.NewDeadlineQueue(lockfree.DropExpired)
+	var dropped []any
+	q.OnExpired = func(v any, _ time.Time) { dropped = append(dropped, v) }
+
+	now := time.Now()
+	q.Enqueue("expired", now.Add(-time.Second))
+	q.Enqueue("valid", now.Add(time.Minute))
+
+	v, _, ok := q.Dequeue()
+	if !ok || v != "valid" {
+		t.Fatalf("want valid, got %v", v)
+	}
+	if len(dropped) != 1 || dropped[0] != "expired" {
+		t.Fatalf("expired item not reported: %v", dropped)
+	}
+	if q.Length() != 0 {
+		t.Fatalf("length wrong: %d", q.Length())
+	}
+}
+
+func TestDeadlineQueuePeek(t *testing.T) {
+	q := lockfree.NewDeadlineQueue(lockfree.DropExpired)
+	var dropped []any
+	q.OnExpired = func(v any, _ time.Time) { dropped = append(dropped, v) }
+	if _, _, ok := q.Peek(); ok {
+		t.Fatalf("peek empty queue returns ok")
+	}
+
+	now := time.Now()
+	q.Enqueue("late", now.Add(time.Hour))
+	q.Enqueue("expired", now.Add(-time.Second))
+	q.Enqueue("soon", now.Add(time.Minute))
+
+	for i := 0; i < 2; i++ {
+		v, d, ok := q.Peek()
+		if !ok || v != "soon" || !d.Equal(now.Add(time.Minute)) {
+			t.Fatalf("want soon, got %v %v", v, d)
+		}
+	}
+	if len(dropped) != 1 || dropped[0] != "expired" {
+		t.Fatalf("expired item not reported: %v", dropped)
+	}
+	if q.Length() != 2 {
+		t.Fatalf("peek must not remove, length %d", q.Length())
+	}
+	if v, _, _ := q.Dequeue(); v != "soon" {
+		t.Fatalf("want soon, got %v", v)
+	}
+}
@@ -0,0 +1,107 @@
+package lockfreequeue
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type dedupRecord struct {
+	key string
+	at  time.Time
+}
+
+// DedupQueue 包装一个队列，在时间窗口内丢弃相同键的重复元素：
+// 某个键的元素被接受后，window 内再次出现的同键元素都会被丢弃，窗口不会因重复元素而延长。
+// 适用于上游抖动导致同一告警或事件反复出现的场景。
+//
+// 由于窗口长度固定，键的过期顺序与接受顺序一致，过期记录按 FIFO 顺序在入队时顺带清理，
+// 每次清理的均摊复杂度为 O(1)。
+type DedupQueue struct {
+	q      *Queue
+	key    func(v any) string
+	window time.Duration
+	now    func() time.Time
+
+	mu      sync.Mutex
+	seen    map[string]time.Time
+	expiry  []dedupRecord
+	head    int
+	dropped uint64
+}
+
+// NewDedupQueue 创建一个包装 q、按 key 在 window 内去重的 DedupQueue。
+func NewDedupQueue(q *Queue, window time.Duration, key func(v any) string) *DedupQueue {
+	return &DedupQueue{
+		q:      q,
+		key:    key,
+		window: window,
+		now:    time.Now,
+		seen:   make(map[string]time.Time),
+	}
+}
+
+// Enqueue 将 v 添加到队列末尾；若窗口内已接受过同键元素则丢弃 v。
+func (d *DedupQueue) Enqueue(v any) {
+	d.TryEnqueue(v)
+}
+
+// TryEnqueue 与 Enqueue 相同，并报告 v 是被接受（true）还是作为重复元素被丢弃（false）。
+func (d *DedupQueue) TryEnqueue(v any) bool {
+	k := d.key(v)
+	now := d.now()
+
+	d.mu.Lock()
+	d.expire(now)
+	if _, ok := d.seen[k]; ok {
+		d.mu.Unlock()
+		atomic.AddUint64(&d.dropped, 1)
+		return false
+	}
+	d.seen[k] = now
+	d.expiry = append(d.expiry, dedupRecord{key: k, at: now})
+	d.mu.Unlock()
+
+	d.q.Enqueue(v)
+	return true
+}
+
+// Dequeue 从队列中移除并返回一个元素。队列为空时返回 nil。
+func (d *DedupQueue) Dequeue() any {
+	return d.q.Dequeue()
+}
+
+// Length returns the length of the underlying queue.
+func (d *DedupQueue) Length() uint64 {
+	return d.q.Length()
+}
+
+// Len returns the length of the underlying queue as an int.
+func (d *DedupQueue) Len() int {
+	return d.q.Len()
+}
+
+// Close 关闭底层队列。
+func (d *DedupQueue) Close() error {
+	return d.q.Close()
+}
+
+// Dropped 返回因重复而被丢弃的元素个数。
+func (d *DedupQueue) Dropped() uint64 {
+	return atomic.LoadUint64(&d.dropped)
+}
+
+// expire 删除窗口已经结束的键，调用方必须持有 d.mu。
+func (d *DedupQueue) expire(now time.Time) {
+	for d.head < len(d.expiry) && now.Sub(d.expiry[d.head].at) >= d.window {
+		delete(d.seen, d.expiry[d.head].key)
+		d.expiry[d.head] = dedupRecord{}
+		d.head++
+	}
+	// 已清理的前缀超过一半时压缩切片，避免底层数组无限增长
+	if d.head > 0 && d.head*2 >= len(d.expiry) {
+		n := copy(d.expiry, d.expiry[d.head:])
+		d.expiry = d.expiry[:n]
+		d.head = 0
+	}
+}
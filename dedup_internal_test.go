@@ -0,0 +1,26 @@
+package lockfreequeue
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDedupWindowBoundary(t *testing.T) {
+	now := time.Unix(0, 0)
+	d := NewDedupQueue(NewQueue(), time.Second, func(v any) string { return v.(string) })
+	d.now = func() time.Time { return now }
+
+	d.Enqueue("a")
+	now = now.Add(time.Second - time.Nanosecond)
+	if d.TryEnqueue("a") {
+		t.Fatalf("duplicate accepted inside the window")
+	}
+	// 重复元素不会延长窗口
+	now = now.Add(time.Nanosecond)
+	if !d.TryEnqueue("a") {
+		t.Fatalf("key not accepted once the window ended")
+	}
+	if len(d.seen) != 1 || len(d.expiry)-d.head != 1 {
+		t.Fatalf("expired records not cleaned up: seen=%d expiry=%d", len(d.seen), len(d.expiry)-d.head)
+	}
+}
@@ -0,0 +1,33 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestDedupQueue(t *testing.T) {
+	d := lockfree.NewDedupQueue(lockfree.NewQueue(), 200*time.Millisecond, func(v any) string { return v.(string) })
+
+	if !d.TryEnqueue("disk-full") {
+		t.Fatalf("first occurrence dropped")
+	}
+	if d.TryEnqueue("disk-full") {
+		t.Fatalf("duplicate within window accepted")
+	}
+	if !d.TryEnqueue("cpu-high") {
+		t.Fatalf("different key dropped")
+	}
+	if d.Length() != 2 || d.Dropped() != 1 {
+		t.Fatalf("length %d, dropped %d", d.Length(), d.Dropped())
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	if !d.TryEnqueue("disk-full") {
+		t.Fatalf("key not accepted after window expired")
+	}
+	if v := d.Dequeue(); v != "disk-full" {
+		t.Fatalf("want disk-full, got %v", v)
+	}
+}
@@ -0,0 +1,161 @@
+package lockfreequeue
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// stickyReplicas 是每个 worker 在一致性哈希环上的虚拟节点数。
+const stickyReplicas = 64
+
+type stickyPoint struct {
+	hash   uint64
+	worker int
+}
+
+type stickyWorker struct {
+	q    *Queue
+	wake chan struct{}
+	quit chan struct{}
+}
+
+// StickyDispatcher 把元素分发给一组 worker goroutine，同一个键的元素总是交给同一个 worker 处理。
+// 每个 worker 拥有自己的队列并按入队顺序串行处理，因此同键元素既获得缓存亲和性，也被串行化执行。
+//
+// 键到 worker 的映射使用带虚拟节点的一致性哈希，Resize 改变 worker 数量时只有约 1/n 的键会迁移。
+// 为了在迁移时保持同键元素的顺序，Resize 会暂停分发并等待所有已分发的元素处理完毕后再重建映射。
+type StickyDispatcher struct {
+	key     func(v any) string
+	handler func(worker int, v any)
+
+	mu       sync.RWMutex // Dispatch 持有读锁，Resize 与 Close 持有写锁
+	ring     []stickyPoint
+	workers  []*stickyWorker
+	inflight sync.WaitGroup // 已分发但尚未处理完的元素
+	running  sync.WaitGroup // 运行中的 worker goroutine
+	closed   bool
+}
+
+// NewStickyDispatcher 创建一个拥有 workers 个 worker 的 StickyDispatcher，workers 小于 1 时视为 1。
+// handler 在 worker 自己的 goroutine 中被调用，worker 为其编号 [0, Workers())。
+// handler 不能调用 Resize 或 Close，否则会死锁。
+func NewStickyDispatcher(workers int, key func(v any) string, handler func(worker int, v any)) *StickyDispatcher {
+	d := &StickyDispatcher{key: key, handler: handler}
+	d.resize(max(workers, 1))
+	return d
+}
+
+// Dispatch 把 v 交给其键对应的 worker。Dispatcher 已关闭时返回 ErrClosed。
+// Resize 进行期间 Dispatch 会阻塞。
+func (d *StickyDispatcher) Dispatch(v any) error {
+	k := stickyHash(d.key(v))
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrClosed
+	}
+	w := d.workers[d.lookup(k)]
+	d.inflight.Add(1)
+	w.q.Enqueue(v)
+	notify(w.wake)
+	return nil
+}
+
+// WorkerOf 返回 key 当前映射到的 worker 编号。
+func (d *StickyDispatcher) WorkerOf(key string) int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.lookup(stickyHash(key))
+}
+
+// Workers 返回当前的 worker 数量。
+func (d *StickyDispatcher) Workers() int {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return len(d.workers)
+}
+
+// Resize 把 worker 数量调整为 n（小于 1 时视为 1）。
+// 调用会等待所有已分发的元素处理完毕，期间新的 Dispatch 被阻塞。Dispatcher 已关闭时返回 ErrClosed。
+func (d *StickyDispatcher) Resize(n int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return ErrClosed
+	}
+	d.inflight.Wait()
+	d.resize(max(n, 1))
+	return nil
+}
+
+// Close 等待所有已分发的元素处理完毕后停止全部 worker。重复调用返回 ErrClosed。
+func (d *StickyDispatcher) Close() error {
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return ErrClosed
+	}
+	d.closed = true
+	d.inflight.Wait()
+	d.resize(0)
+	d.mu.Unlock()
+	d.running.Wait()
+	return nil
+}
+
+// resize 启动或停止 worker 并重建哈希环，调用方必须持有写锁且没有在途元素。
+func (d *StickyDispatcher) resize(n int) {
+	for len(d.workers) > n {
+		last := len(d.workers) - 1
+		close(d.workers[last].quit)
+		d.workers = d.workers[:last]
+	}
+	for len(d.workers) < n {
+		w := &stickyWorker{q: NewQueue(), wake: make(chan struct{}, 1), quit: make(chan struct{})}
+		d.workers = append(d.workers, w)
+		d.running.Add(1)
+		go d.run(len(d.workers)-1, w)
+	}
+
+	d.ring = d.ring[:0]
+	for i := range d.workers {
+		for r := 0; r < stickyReplicas; r++ {
+			d.ring = append(d.ring, stickyPoint{hash: stickyHash(strconv.Itoa(i) + "#" + strconv.Itoa(r)), worker: i})
+		}
+	}
+	sort.Slice(d.ring, func(i, j int) bool { return d.ring[i].hash < d.ring[j].hash })
+}
+
+// lookup 返回哈希值 k 顺时针方向上第一个虚拟节点所属的 worker，调用方必须持有锁。
+func (d *StickyDispatcher) lookup(k uint64) int {
+	i := sort.Search(len(d.ring), func(i int) bool { return d.ring[i].hash >= k })
+	if i == len(d.ring) {
+		i = 0
+	}
+	return d.ring[i].worker
+}
+
+func (d *StickyDispatcher) run(id int, w *stickyWorker) {
+	defer d.running.Done()
+	for {
+		if v := w.q.Dequeue(); v != nil {
+			d.handler(id, v)
+			d.inflight.Done()
+			continue
+		}
+		select {
+		case <-w.wake:
+		case <-w.quit:
+			// 停止前队列一定已经为空：Resize 与 Close 会先等待在途元素处理完毕
+			return
+		}
+	}
+}
+
+func stickyHash(key string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return h.Sum64()
+}
@@ -0,0 +1,109 @@
+package lockfreequeue_test
+
+import (
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+type keyed struct {
+	key string
+	seq int
+}
+
+func TestStickyDispatcher(t *testing.T) {
+	var mu sync.Mutex
+	owner := make(map[string]int)
+	last := make(map[string]int)
+	d := lockfree.NewStickyDispatcher(4, func(v any) string { return v.(keyed).key }, func(worker int, v any) {
+		k := v.(keyed)
+		mu.Lock()
+		defer mu.Unlock()
+		if w, ok := owner[k.key]; ok && w != worker {
+			t.Errorf("key %s moved from worker %d to %d", k.key, w, worker)
+		}
+		owner[k.key] = worker
+		if k.seq != last[k.key]+1 {
+			t.Errorf("key %s out of order: %d after %d", k.key, k.seq, last[k.key])
+		}
+		last[k.key] = k.seq
+	})
+
+	for seq := 1; seq <= 100; seq++ {
+		for k := 0; k < 20; k++ {
+			if err := d.Dispatch(keyed{key: strconv.Itoa(k), seq: seq}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	for k := 0; k < 20; k++ {
+		if last[strconv.Itoa(k)] != 100 {
+			t.Fatalf("key %d processed %d items", k, last[strconv.Itoa(k)])
+		}
+	}
+	if err := d.Dispatch(keyed{}); !errors.Is(err, lockfree.ErrClosed) {
+		t.Fatalf("dispatch after close: %v", err)
+	}
+	if err := d.Close(); !errors.Is(err, lockfree.ErrClosed) {
+		t.Fatalf("second close: %v", err)
+	}
+}
+
+func TestStickyDispatcherResize(t *testing.T) {
+	var mu sync.Mutex
+	last := make(map[string]int)
+	d := lockfree.NewStickyDispatcher(4, func(v any) string { return v.(keyed).key }, func(_ int, v any) {
+		k := v.(keyed)
+		mu.Lock()
+		defer mu.Unlock()
+		if k.seq != last[k.key]+1 {
+			t.Errorf("key %s out of order: %d after %d", k.key, k.seq, last[k.key])
+		}
+		last[k.key] = k.seq
+	})
+	defer d.Close()
+
+	const keys = 1000
+	before := make([]int, keys)
+	for k := range before {
+		before[k] = d.WorkerOf(strconv.Itoa(k))
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for seq := 1; seq <= 50; seq++ {
+			for k := 0; k < 10; k++ {
+				d.Dispatch(keyed{key: strconv.Itoa(k), seq: seq})
+			}
+		}
+	}()
+	if err := d.Resize(5); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	if d.Workers() != 5 {
+		t.Fatalf("want 5 workers, got %d", d.Workers())
+	}
+	moved := 0
+	for k := range before {
+		if w := d.WorkerOf(strconv.Itoa(k)); w != before[k] {
+			if w != 4 {
+				t.Fatalf("key %d moved between surviving workers %d -> %d", k, before[k], w)
+			}
+			moved++
+		}
+	}
+	// 一致性哈希只迁移约 1/5 的键
+	if moved == 0 || moved > keys/2 {
+		t.Fatalf("unexpected number of moved keys: %d", moved)
+	}
+}
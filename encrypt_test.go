@@ -0,0 +1,43 @@
+package lockfreequeue_test
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestEncryptedQueue(t *testing.T) {
+	block, err := aes.NewCipher(make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q := lockfree.NewQueue()
+	e := lockfree.NewEncryptedQueue(q, aead)
+	secret := []byte("card number")
+	if err := e.Enqueue(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	// 队列中保存的不是明文
+	raw := q.Dequeue().([]byte)
+	if bytes.Contains(raw, secret) {
+		t.Fatalf("payload stored in plaintext")
+	}
+	q.Enqueue(raw)
+
+	p, ok, err := e.Dequeue()
+	if err != nil || !ok || !bytes.Equal(p, secret) {
+		t.Fatalf("decrypt wrong: %q, %v, %v", p, ok, err)
+	}
+	if _, ok, _ := e.Dequeue(); ok {
+		t.Fatalf("dequeue empty queue returns ok")
+	}
+}
@@ -0,0 +1,33 @@
+package lockfreequeue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrFull 表示缓冲区或有界队列已满，数据未能全部写入。
+	ErrFull = errors.New("lockfreequeue: queue is full")
+	// ErrClosed 表示队列或分发器已关闭。
+	ErrClosed = errors.New("lockfreequeue: queue is closed")
+	// ErrTimeout 表示操作在超时或截止时间到达前未能完成。
+	ErrTimeout = errors.New("lockfreequeue: operation timed out")
+	// ErrCancelled 表示操作因 context 被取消而中止。
+	ErrCancelled = errors.New("lockfreequeue: operation cancelled")
+)
+
+// ctxErr 将 ctx 的错误转换为本包的错误值，同时保留原始错误，
+// 因此 errors.Is(err, ErrTimeout) 与 errors.Is(err, context.DeadlineExceeded) 都成立。
+// ctx 尚未结束时返回 nil。
+func ctxErr(ctx context.Context) error {
+	err := ctx.Err()
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	default:
+		return fmt.Errorf("%w: %w", ErrCancelled, err)
+	}
+}
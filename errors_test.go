@@ -0,0 +1,24 @@
+package lockfreequeue_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestCancelledError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := lockfree.NewCoalescer[int]()
+	call, _ := c.Enqueue(1, func() (any, error) { return nil, nil })
+	_, err := call.Wait(ctx)
+	if !errors.Is(err, lockfree.ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled error, got %v", err)
+	}
+	if errors.Is(err, lockfree.ErrTimeout) {
+		t.Fatalf("cancellation reported as timeout")
+	}
+}
@@ -0,0 +1,103 @@
+package lockfreequeue
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+type node[T any] struct {
+	next unsafe.Pointer
+	v    T
+}
+
+func loadnode[T any](p *unsafe.Pointer) *node[T] {
+	return (*node[T])(atomic.LoadPointer(p))
+}
+
+func casnode[T any](p *unsafe.Pointer, old, new *node[T]) bool {
+	return atomic.CompareAndSwapPointer(p, unsafe.Pointer(old), unsafe.Pointer(new))
+}
+
+// QueueOf 是元素类型为 T 的无锁队列，与 Queue 使用相同的 Michael-Scott 算法。
+// 元素直接保存在节点中，入队时不需要把值装箱为 interface{}，出队时也不需要类型断言，
+// Dequeue 的第二个返回值区分了“队列为空”与“取出了零值”。
+//
+// 节点不会被复用，而是交给垃圾回收器，因此不存在节点被复用时的 ABA 问题；
+// 代价是每次入队分配一个节点（值类型 T 与节点一起分配，不再另外装箱）。
+// 出队后，最后取出的元素会被新的头节点引用，直到下一次出队。
+type QueueOf[T any] struct {
+	head     unsafe.Pointer
+	tail     unsafe.Pointer
+	enqueued uint64
+	dequeued uint64
+}
+
+// NewQueueOf 创建并返回一个元素类型为 T 的空队列。
+func NewQueueOf[T any]() *QueueOf[T] {
+	head := unsafe.Pointer(&node[T]{})
+	return &QueueOf[T]{head: head, tail: head}
+}
+
+// Enqueue 将 v 添加到队列末尾。
+func (q *QueueOf[T]) Enqueue(v T) {
+	i := &node[T]{v: v}
+	var b backoff
+	for {
+		tail := loadnode[T](&q.tail)
+		next := loadnode[T](&tail.next)
+		if tail == loadnode[T](&q.tail) {
+			if next == nil {
+				if casnode(&tail.next, next, i) {
+					casnode(&q.tail, tail, i)
+					atomic.AddUint64(&q.enqueued, 1)
+					return
+				}
+			} else {
+				// 尾部指针落后，帮助推进
+				casnode(&q.tail, tail, next)
+			}
+		}
+		b.wait()
+	}
+}
+
+// Dequeue 从队列中移除并返回一个元素。队列为空时返回零值与 false。
+func (q *QueueOf[T]) Dequeue() (T, bool) {
+	var b backoff
+	for {
+		first := loadnode[T](&q.head)
+		last := loadnode[T](&q.tail)
+		next := loadnode[T](&first.next)
+		if first == loadnode[T](&q.head) {
+			if first == last {
+				if next == nil {
+					var zero T
+					return zero, false
+				}
+				casnode(&q.tail, last, next)
+			} else {
+				// 在交换头部指针之前读取值，CAS 失败时丢弃
+				v := next.v
+				if casnode(&q.head, first, next) {
+					atomic.AddUint64(&q.dequeued, 1)
+					return v, true
+				}
+			}
+		}
+		b.wait()
+	}
+}
+
+// Length returns the length of the queue.
+func (q *QueueOf[T]) Length() uint64 {
+	d := atomic.LoadUint64(&q.dequeued)
+	if e := atomic.LoadUint64(&q.enqueued); e > d {
+		return e - d
+	}
+	return 0
+}
+
+// Len 以 int 返回队列的长度。
+func (q *QueueOf[T]) Len() int {
+	return int(q.Length())
+}
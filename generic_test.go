@@ -0,0 +1,101 @@
+package lockfreequeue_test
+
+import (
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestQueueOf(t *testing.T) {
+	q := lockfree.NewQueueOf[*int]()
+	if _, ok := q.Dequeue(); ok {
+		t.Fatal("empty queue: want ok=false")
+	}
+	q.Enqueue(nil)
+	p := new(int)
+	q.Enqueue(p)
+	if q.Len() != 2 {
+		t.Fatalf("want len 2, got %d", q.Len())
+	}
+	if v, ok := q.Dequeue(); !ok || v != nil {
+		t.Fatalf("want queued nil, got %v, %v", v, ok)
+	}
+	if v, ok := q.Dequeue(); !ok || v != p {
+		t.Fatalf("want %p, got %v, %v", p, v, ok)
+	}
+	if _, ok := q.Dequeue(); ok {
+		t.Fatal("drained queue: want ok=false")
+	}
+}
+
+func TestQueueOfConcurrent(t *testing.T) {
+	const producers, per = 4, 10000
+	q := lockfree.NewQueueOf[int]()
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func(p int) {
+			defer wg.Done()
+			for i := 0; i < per; i++ {
+				q.Enqueue(p*per + i)
+			}
+		}(p)
+	}
+
+	seen := make([]bool, producers*per)
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+	for n := 0; n < producers*per; {
+		v, ok := q.Dequeue()
+		if !ok {
+			continue
+		}
+		if seen[v] {
+			t.Fatalf("%d dequeued twice", v)
+		}
+		seen[v] = true
+		// 同一生产者的元素保持入队顺序
+		if p := v / per; v <= last[p] {
+			t.Fatalf("producer %d: %d after %d", p, v, last[p])
+		} else {
+			last[p] = v
+		}
+		n++
+	}
+	wg.Wait()
+	if q.Len() != 0 {
+		t.Fatalf("want empty queue, got len %d", q.Len())
+	}
+}
+
+func BenchmarkQueueOf(b *testing.B) {
+	b.Run("Queue", func(b *testing.B) {
+		q := lockfree.NewQueue()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 1 << 20
+			for pb.Next() {
+				i++
+				q.Enqueue(i)
+				if v, ok := q.Dequeue().(int); ok {
+					i += v & 1
+				}
+			}
+		})
+	})
+	b.Run("QueueOf", func(b *testing.B) {
+		q := lockfree.NewQueueOf[int]()
+		b.RunParallel(func(pb *testing.PB) {
+			i := 1 << 20
+			for pb.Next() {
+				i++
+				q.Enqueue(i)
+				if v, ok := q.Dequeue(); ok {
+					i += v & 1
+				}
+			}
+		})
+	})
+}
@@ -0,0 +1,48 @@
+package lockfreequeue
+
+import "sync/atomic"
+
+// Handle 是通过 EnqueueHandle 入队的元素的句柄，支持 O(1) 的尽力删除。
+// 删除是逻辑删除：元素仍留在链表中，直到某次 Dequeue 到达它时才被跳过并物理移除，
+// 因此在那之前它仍计入 Length。
+type Handle struct {
+	v     any
+	state int32
+}
+
+// EnqueueHandle 将 v 添加到队列末尾，并返回可用于删除该元素的句柄。
+func (q *Queue) EnqueueHandle(v any) *Handle {
+	h := &Handle{v: v}
+	q.Enqueue(h)
+	return h
+}
+
+// Remove 逻辑删除该元素。
+// 返回 true 表示删除成功，之后任何 Dequeue 都不会返回它；
+// 返回 false 表示元素已经被出队或已经被删除。
+func (h *Handle) Remove() bool {
+	return h.claim()
+}
+
+// Value 返回句柄对应的元素。
+func (h *Handle) Value() any {
+	return h.v
+}
+
+// claim 原子地取得元素的所有权，只有第一个调用者会成功。
+func (h *Handle) claim() bool {
+	return atomic.CompareAndSwapInt32(&h.state, 0, 1)
+}
+
+// unwrap 处理出队得到的原始值：普通值原样返回；*Handle 则与 Handle.Remove 竞争，
+// 抢到的一方拥有该元素。返回 false 表示该元素已被删除，调用方应继续出队。
+func unwrap(v any) (any, bool) {
+	h, ok := v.(*Handle)
+	if !ok {
+		return v, true
+	}
+	if h.claim() {
+		return h.v, true
+	}
+	return nil, false
+}
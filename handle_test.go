@@ -0,0 +1,72 @@
+package lockfreequeue_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestHandleRemove(t *testing.T) {
+	q := lockfree.NewQueue()
+	q.EnqueueHandle(1)
+	h := q.EnqueueHandle(2)
+	q.EnqueueHandle(3)
+
+	if !h.Remove() {
+		t.Fatalf("remove of queued item failed")
+	}
+	if h.Remove() {
+		t.Fatalf("second remove must fail")
+	}
+	if v := q.Dequeue(); v != 1 {
+		t.Fatalf("want 1, got %v", v)
+	}
+	if v := q.Dequeue(); v != 3 {
+		t.Fatalf("removed item was dequeued, got %v", v)
+	}
+	if q.Dequeue() != nil || q.Length() != 0 {
+		t.Fatalf("queue should be empty, length %d", q.Length())
+	}
+
+	h = q.EnqueueHandle(4)
+	q.Dequeue()
+	if h.Remove() {
+		t.Fatalf("remove after dequeue must fail")
+	}
+}
+
+func TestHandleRemoveConcurrent(t *testing.T) {
+	q := lockfree.NewQueue()
+	const n = 10000
+	handles := make([]*lockfree.Handle, n)
+	for i := range handles {
+		handles[i] = q.EnqueueHandle(i)
+	}
+
+	var removed, dequeued int64
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for _, h := range handles {
+			if h.Remove() {
+				atomic.AddInt64(&removed, 1)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for q.Dequeue() != nil {
+			atomic.AddInt64(&dequeued, 1)
+		}
+	}()
+	wg.Wait()
+	for q.Dequeue() != nil {
+		dequeued++
+	}
+	if removed+dequeued != n {
+		t.Fatalf("each item must be removed or dequeued exactly once: removed %d, dequeued %d", removed, dequeued)
+	}
+}
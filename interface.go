@@ -0,0 +1,77 @@
+package lockfreequeue
+
+import "sync"
+
+// Interface 是本包中以 any 为元素类型的各种队列共同实现的接口，
+// 应用程序与基准测试可以据此在不同实现之间切换而无需修改代码。
+//
+// Dequeue 在队列为空时返回 nil，不会阻塞。
+// Close 表示不再有新元素入队，重复调用返回 ErrClosed；调用方应保证 Close 之后不再调用 Enqueue。
+type Interface interface {
+	Enqueue(v any)
+	Dequeue() any
+	Len() int
+	Close() error
+}
+
+var (
+	_ Interface = (*Queue)(nil)
+	_ Interface = (*ChanQueue)(nil)
+	_ Interface = (*BoundedQueue)(nil)
+	_ Interface = (*AuditQueue)(nil)
+	_ Interface = (*DedupQueue)(nil)
+	_ Interface = (*MirrorQueue)(nil)
+	_ Interface = (*ReservoirTap)(nil)
+	_ Interface = (*TopKTap)(nil)
+)
+
+// ChanQueue 把一个 channel 适配为 Interface：Enqueue 在 channel 已满时阻塞，Dequeue 不阻塞。
+type ChanQueue struct {
+	ch   chan any
+	once sync.Once
+}
+
+// NewChanQueue 创建一个以容量为 size 的 channel 为存储的 ChanQueue。
+func NewChanQueue(size int) *ChanQueue {
+	return FromChan(make(chan any, size))
+}
+
+// FromChan 把已有的 channel 适配为 ChanQueue，Close 会关闭 ch。
+func FromChan(ch chan any) *ChanQueue {
+	return &ChanQueue{ch: ch}
+}
+
+// Enqueue 向 channel 发送 v，channel 已满时阻塞。
+func (c *ChanQueue) Enqueue(v any) {
+	c.ch <- v
+}
+
+// Dequeue 不阻塞地从 channel 接收一个元素，channel 为空或已关闭且取空时返回 nil。
+func (c *ChanQueue) Dequeue() any {
+	select {
+	case v := <-c.ch:
+		return v
+	default:
+		return nil
+	}
+}
+
+// Len 返回 channel 中缓冲的元素个数。
+func (c *ChanQueue) Len() int {
+	return len(c.ch)
+}
+
+// Close 关闭 channel，重复调用返回 ErrClosed。
+func (c *ChanQueue) Close() error {
+	err := ErrClosed
+	c.once.Do(func() {
+		close(c.ch)
+		err = nil
+	})
+	return err
+}
+
+// Chan 返回底层的 channel，可用于 select 或 range。
+func (c *ChanQueue) Chan() <-chan any {
+	return c.ch
+}
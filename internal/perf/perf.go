@@ -0,0 +1,42 @@
+// Package perf 在 Linux 上通过 perf_event_open 读取硬件性能计数器（周期、指令、缓存未命中、分支预测失败），
+// 用于给基准测试中的缓存行填充、分片等优化提供硬件层面的证据。其他平台上 Open 返回 ErrUnsupported。
+package perf
+
+import "errors"
+
+// ErrUnsupported 表示当前平台不支持性能计数器。
+var ErrUnsupported = errors.New("perf: hardware counters are not supported on this platform")
+
+// Event 是一个硬件事件。
+type Event int
+
+const (
+	Cycles Event = iota
+	Instructions
+	CacheReferences
+	CacheMisses
+	BranchInstructions
+	BranchMisses
+)
+
+// String 返回事件的名称。
+func (e Event) String() string {
+	switch e {
+	case Cycles:
+		return "cycles"
+	case Instructions:
+		return "instructions"
+	case CacheReferences:
+		return "cache-references"
+	case CacheMisses:
+		return "cache-misses"
+	case BranchInstructions:
+		return "branches"
+	case BranchMisses:
+		return "branch-misses"
+	}
+	return "unknown"
+}
+
+// DefaultEvents 是 Open 未指定事件时使用的事件集合。
+var DefaultEvents = []Event{Cycles, Instructions, CacheMisses, BranchMisses}
@@ -0,0 +1,190 @@
+package perf
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"strconv"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	perfTypeHardware = 0
+	perfFlagCloexec  = 1 << 3
+
+	// attr.flags 中的位
+	flagDisabled      = 1 << 0
+	flagInherit       = 1 << 1
+	flagExcludeKernel = 1 << 5
+	flagExcludeHV     = 1 << 6
+
+	// attr.read_format 中的位，用于在计数器被多路复用时按运行时间比例换算
+	formatTotalTimeEnabled = 1 << 0
+	formatTotalTimeRunning = 1 << 1
+
+	iocEnable  = 0x2400
+	iocDisable = 0x2401
+	iocReset   = 0x2403
+)
+
+// eventAttr 是 struct perf_event_attr 的 PERF_ATTR_SIZE_VER0 版本（64 字节）。
+type eventAttr struct {
+	typ          uint32
+	size         uint32
+	config       uint64
+	samplePeriod uint64
+	sampleType   uint64
+	readFormat   uint64
+	flags        uint64
+	wakeup       uint32
+	bpType       uint32
+	config1      uint64
+}
+
+// Counters 是一组打开的硬件计数器。
+// Open 时为进程当前的每个线程（/proc/self/task）分别打开计数器并设置 inherit，
+// 因此既统计已有的全部线程，也统计之后由它们创建的线程。
+// 同时打开的事件多于硬件计数器数量时内核会分时复用，Stop 会按实际运行时间比例换算计数。
+type Counters struct {
+	events []Event
+	fds    [][]int // 每个事件在各线程上的计数器
+	// IOC_RESET 只清零计数值而不清零时间，base 记录 Start 时各计数器的 time_enabled 与 time_running
+	base map[int][2]uint64
+}
+
+// Open 打开 events 指定的计数器，events 为空时使用 DefaultEvents。计数器初始为停止状态。
+// 只统计用户态；当内核的 perf_event_paranoid 设置禁止访问时返回错误。
+func Open(events ...Event) (*Counters, error) {
+	if len(events) == 0 {
+		events = DefaultEvents
+	}
+	tids, err := threads()
+	if err != nil {
+		return nil, err
+	}
+	c := &Counters{events: events, fds: make([][]int, len(events))}
+	for i, e := range events {
+		attr := eventAttr{
+			typ:        perfTypeHardware,
+			size:       uint32(unsafe.Sizeof(eventAttr{})),
+			config:     uint64(e),
+			readFormat: formatTotalTimeEnabled | formatTotalTimeRunning,
+			flags:      flagDisabled | flagInherit | flagExcludeKernel | flagExcludeHV,
+		}
+		for _, tid := range tids {
+			fd, _, errno := syscall.Syscall6(syscall.SYS_PERF_EVENT_OPEN,
+				uintptr(unsafe.Pointer(&attr)), uintptr(tid), ^uintptr(0), ^uintptr(0), perfFlagCloexec, 0)
+			if errno == syscall.ESRCH {
+				// 线程在枚举之后已经退出
+				continue
+			}
+			if errno != 0 {
+				c.Close()
+				return nil, errno
+			}
+			c.fds[i] = append(c.fds[i], int(fd))
+		}
+	}
+	return c, nil
+}
+
+// threads 返回当前进程全部线程的 ID。
+func threads() ([]int, error) {
+	entries, err := os.ReadDir("/proc/self/task")
+	if err != nil {
+		return nil, err
+	}
+	tids := make([]int, 0, len(entries))
+	for _, e := range entries {
+		if tid, err := strconv.Atoi(e.Name()); err == nil {
+			tids = append(tids, tid)
+		}
+	}
+	if len(tids) == 0 {
+		return nil, errors.New("perf: no threads found in /proc/self/task")
+	}
+	return tids, nil
+}
+
+// Start 清零并启动全部计数器。
+func (c *Counters) Start() error {
+	if c.base == nil {
+		c.base = make(map[int][2]uint64)
+	}
+	for _, fds := range c.fds {
+		for _, fd := range fds {
+			if err := ioctl(fd, iocReset); err != nil {
+				return err
+			}
+			_, enabled, running, err := read(fd)
+			if err != nil {
+				return err
+			}
+			c.base[fd] = [2]uint64{enabled, running}
+			if err := ioctl(fd, iocEnable); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Stop 停止全部计数器并返回自 Start 以来各事件在全部线程上的计数之和。
+func (c *Counters) Stop() (map[Event]uint64, error) {
+	counts := make(map[Event]uint64, len(c.fds))
+	for i, fds := range c.fds {
+		var total uint64
+		for _, fd := range fds {
+			if err := ioctl(fd, iocDisable); err != nil {
+				return nil, err
+			}
+			value, enabled, running, err := read(fd)
+			if err != nil {
+				return nil, err
+			}
+			base := c.base[fd]
+			total += scale(value, enabled-base[0], running-base[1])
+		}
+		counts[c.events[i]] = total
+	}
+	return counts, nil
+}
+
+// read 读取计数器的值以及累计的 time_enabled 与 time_running。
+func read(fd int) (value, enabled, running uint64, err error) {
+	var buf [24]byte
+	if _, err := syscall.Read(fd, buf[:]); err != nil {
+		return 0, 0, 0, err
+	}
+	return binary.NativeEndian.Uint64(buf[:]), binary.NativeEndian.Uint64(buf[8:]), binary.NativeEndian.Uint64(buf[16:]), nil
+}
+
+// scale 把被多路复用的计数器的读数按 enabled/running 换算为整个启用期间的估计值。
+func scale(value, enabled, running uint64) uint64 {
+	if running == 0 || running >= enabled {
+		return value
+	}
+	return uint64(float64(value) * float64(enabled) / float64(running))
+}
+
+// Close 关闭全部计数器。
+func (c *Counters) Close() error {
+	var first error
+	for _, fds := range c.fds {
+		for _, fd := range fds {
+			if err := syscall.Close(fd); err != nil && first == nil {
+				first = err
+			}
+		}
+	}
+	c.fds = nil
+	return first
+}
+
+func ioctl(fd int, req uintptr) error {
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), req, 0); errno != 0 {
+		return errno
+	}
+	return nil
+}
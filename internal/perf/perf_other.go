@@ -0,0 +1,26 @@
+//go:build !linux
+
+package perf
+
+// Counters 是一组打开的硬件计数器，在当前平台上不可用。
+type Counters struct{}
+
+// Open 在当前平台上总是返回 ErrUnsupported。
+func Open(events ...Event) (*Counters, error) {
+	return nil, ErrUnsupported
+}
+
+// Start 实现与 Linux 版本相同的接口。
+func (c *Counters) Start() error {
+	return ErrUnsupported
+}
+
+// Stop 实现与 Linux 版本相同的接口。
+func (c *Counters) Stop() (map[Event]uint64, error) {
+	return nil, ErrUnsupported
+}
+
+// Close 实现与 Linux 版本相同的接口。
+func (c *Counters) Close() error {
+	return nil
+}
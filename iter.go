@@ -0,0 +1,37 @@
+package lockfreequeue
+
+import "sync/atomic"
+
+// Range 从队头到队尾依次对队列中的元素调用 f，f 返回 false 时停止。元素不会被移除。
+//
+// 遍历是弱一致的：与之并发出队的元素仍可能被访问到，并发入队的元素可能被访问到也可能不会。
+// ReclaimEpoch 方式下遍历期间会 pin 住当前的 epoch，节点在遍历结束前不会被复用，
+// 因此 f 不应长时间阻塞，否则已出队节点的回收会被推迟；ReclaimGC 方式下遍历总是安全的。
+// ReclaimPool 方式下节点出队后立即复用，只有在没有并发出队时遍历的结果才可靠。
+// 已被 Handle.Remove 删除的元素会被跳过，通过 EnqueueHandle 入队的元素按其 Value 访问。
+func (q *Queue) Range(f func(v any) bool) {
+	e := q.pin()
+	defer q.unpin(e)
+	for n := loaditem(&loaditem(&q.head).next); n != nil; n = loaditem(&n.next) {
+		v := n.v
+		if h, ok := v.(*Handle); ok {
+			if atomic.LoadInt32(&h.state) != 0 {
+				continue
+			}
+			v = h.v
+		}
+		if !f(v) {
+			return
+		}
+	}
+}
+
+// ToSlice 按从队头到队尾的顺序返回队列中元素的副本，一致性与 Range 相同。队列为空时返回 nil。
+func (q *Queue) ToSlice() []any {
+	var vs []any
+	q.Range(func(v any) bool {
+		vs = append(vs, v)
+		return true
+	})
+	return vs
+}
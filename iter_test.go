@@ -0,0 +1,65 @@
+package lockfreequeue_test
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestRange(t *testing.T) {
+	q := lockfree.NewQueue()
+	if vs := q.ToSlice(); vs != nil {
+		t.Fatalf("empty queue: want nil, got %v", vs)
+	}
+	q.Enqueue(1)
+	q.EnqueueHandle(2).Remove()
+	q.EnqueueHandle(3)
+	q.Enqueue(4)
+	if vs := q.ToSlice(); !reflect.DeepEqual(vs, []any{1, 3, 4}) {
+		t.Fatalf("want [1 3 4], got %v", vs)
+	}
+	var first []any
+	q.Range(func(v any) bool {
+		first = append(first, v)
+		return false
+	})
+	if !reflect.DeepEqual(first, []any{1}) {
+		t.Fatalf("Range must stop when f returns false, got %v", first)
+	}
+	if q.Len() != 4 || q.Dequeue() != 1 {
+		t.Fatal("Range must not remove items")
+	}
+}
+
+func TestRangeConcurrent(t *testing.T) {
+	q := lockfree.NewQueue()
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for w := 0; w < 2; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				q.Enqueue(i)
+				q.Dequeue()
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		q.Range(func(v any) bool {
+			if _, ok := v.(int); !ok {
+				t.Errorf("unexpected value %v", v)
+			}
+			return true
+		})
+	}
+	close(stop)
+	wg.Wait()
+}
@@ -0,0 +1,9 @@
+package lockfreequeue
+
+// NodeLeak 描述一个从池中取出后未归还，或被重复归还的节点。
+type NodeLeak struct {
+	// Stack 是节点被取出时的调用栈；对重复归还的节点是上一次归还时的调用栈。
+	Stack string
+	// DoubleReturn 为 true 表示节点被重复归还。
+	DoubleReturn bool
+}
@@ -0,0 +1,16 @@
+//go:build !lockfreequeue_leakcheck
+
+package lockfreequeue
+
+// leakState 是队列中供泄漏检测使用的状态，未启用检测时不占用空间。
+type leakState struct{}
+
+// LeakReport 返回队列 q 的节点泄漏报告。
+// 只有使用 `-tags lockfreequeue_leakcheck` 构建时才会跟踪节点，否则总是返回 nil。
+func LeakReport(q *Queue) []NodeLeak {
+	return nil
+}
+
+func trackGet(q *Queue, n *directItem) {}
+
+func trackPut(q *Queue, n *directItem) {}
@@ -0,0 +1,86 @@
+//go:build lockfreequeue_leakcheck
+
+package lockfreequeue
+
+import (
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// leakState 保存在队列自身中，随队列一起被回收。
+type leakState struct {
+	mu       sync.Mutex
+	out      map[*directItem]string // 已取出的节点 -> 取出时的调用栈
+	returned map[*directItem]string // 已归还的节点 -> 归还时的调用栈
+	doubles  []NodeLeak
+}
+
+// lockTracker 锁定并返回 q 的跟踪状态，调用方负责解锁。
+func lockTracker(q *Queue) *leakState {
+	t := &q.leaks
+	t.mu.Lock()
+	if t.out == nil {
+		t.out = make(map[*directItem]string)
+		t.returned = make(map[*directItem]string)
+	}
+	return t
+}
+
+// LeakReport 返回队列 q 的节点泄漏报告：已从池中取出、既未归还也不在队列链表中的节点，
+// 以及被重复归还的节点。调用时队列上不能有并发操作。
+func LeakReport(q *Queue) []NodeLeak {
+	t := lockTracker(q)
+	defer t.mu.Unlock()
+
+	live := make(map[*directItem]bool)
+	for n := loaditem(&q.head); n != nil; n = loaditem(&n.next) {
+		live[n] = true
+	}
+	leaks := append([]NodeLeak(nil), t.doubles...)
+	for n, stack := range t.out {
+		if !live[n] {
+			leaks = append(leaks, NodeLeak{Stack: stack})
+		}
+	}
+	return leaks
+}
+
+func trackGet(q *Queue, n *directItem) {
+	stack := callers()
+	t := lockTracker(q)
+	delete(t.returned, n)
+	t.out[n] = stack
+	t.mu.Unlock()
+}
+
+func trackPut(q *Queue, n *directItem) {
+	stack := callers()
+	t := lockTracker(q)
+	if _, ok := t.out[n]; !ok {
+		t.doubles = append(t.doubles, NodeLeak{Stack: t.returned[n], DoubleReturn: true})
+	}
+	delete(t.out, n)
+	t.returned[n] = stack
+	t.mu.Unlock()
+}
+
+func callers() string {
+	pc := make([]uintptr, 32)
+	// 跳过 runtime.Callers、callers 与 trackGet/trackPut
+	frames := runtime.CallersFrames(pc[:runtime.Callers(3, pc)])
+	var b strings.Builder
+	for {
+		f, more := frames.Next()
+		b.WriteString(f.Function)
+		b.WriteString("\n\t")
+		b.WriteString(f.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		b.WriteByte('\n')
+		if !more {
+			return b.String()
+		}
+	}
+}
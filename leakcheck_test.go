@@ -0,0 +1,44 @@
+//go:build lockfreequeue_leakcheck
+
+package lockfreequeue
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLeakReportClean(t *testing.T) {
+	q := NewQueue()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				q.Enqueue(i)
+				q.Dequeue()
+			}
+		}()
+	}
+	wg.Wait()
+	if leaks := LeakReport(q); len(leaks) != 0 {
+		t.Fatalf("unexpected leaks: %+v", leaks)
+	}
+}
+
+func TestLeakReportDetects(t *testing.T) {
+	q := NewQueue()
+	n := q.pool.Get().(*directItem)
+	trackGet(q, n)
+	leaks := LeakReport(q)
+	if len(leaks) != 1 || leaks[0].DoubleReturn || leaks[0].Stack == "" {
+		t.Fatalf("leaked node not reported: %+v", leaks)
+	}
+
+	trackPut(q, n)
+	trackPut(q, n)
+	leaks = LeakReport(q)
+	if len(leaks) != 1 || !leaks[0].DoubleReturn {
+		t.Fatalf("double return not reported: %+v", leaks)
+	}
+}
@@ -0,0 +1,137 @@
+package lockfreequeue
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LifecycleEventKind 标识元素生命周期中的一个阶段。
+type LifecycleEventKind int
+
+const (
+	// EventEnqueued 表示元素已入队。
+	EventEnqueued LifecycleEventKind = iota
+	// EventDequeued 表示元素已被某个消费者取出。
+	EventDequeued
+	// EventAcked 表示消费者已确认处理完成。
+	EventAcked
+)
+
+// String 返回事件类型的名称。
+func (k LifecycleEventKind) String() string {
+	switch k {
+	case EventEnqueued:
+		return "enqueued"
+	case EventDequeued:
+		return "dequeued"
+	case EventAcked:
+		return "acked"
+	}
+	return "unknown"
+}
+
+// LifecycleEvent 是元素生命周期中的一条记录。
+type LifecycleEvent struct {
+	Kind     LifecycleEventKind
+	At       time.Time
+	Consumer string
+}
+
+// ItemTrace 是某个元素按发生顺序排列的全部生命周期事件。
+type ItemTrace struct {
+	ID     uint64
+	Events []LifecycleEvent
+}
+
+type lifecycleItem struct {
+	id uint64
+	v  any
+}
+
+// LifecycleQueue 包装一个队列，为每个元素分配 ID 并记录其入队、出队、确认事件，
+// 可以按 ID 查询，用于排查“消息去哪了”一类的问题。
+// 只保留最近 retain 个元素的记录，更早的记录会被淘汰。
+type LifecycleQueue struct {
+	q      *Queue
+	nextID uint64
+
+	mu     sync.Mutex
+	traces map[uint64]*ItemTrace
+	order  []uint64
+	pos    int
+}
+
+// NewLifecycleQueue 创建一个包装 q、最多保留 retain 条记录的 LifecycleQueue。
+func NewLifecycleQueue(q *Queue, retain int) *LifecycleQueue {
+	if retain < 1 {
+		retain = 1
+	}
+	return &LifecycleQueue{
+		q:      q,
+		traces: make(map[uint64]*ItemTrace, retain),
+		order:  make([]uint64, 0, retain),
+	}
+}
+
+// Enqueue 将 v 添加到队列末尾，并返回分配给它的 ID。
+func (l *LifecycleQueue) Enqueue(v any) uint64 {
+	id := atomic.AddUint64(&l.nextID, 1)
+
+	l.mu.Lock()
+	if len(l.order) < cap(l.order) {
+		l.order = append(l.order, id)
+	} else {
+		// 淘汰最旧的记录
+		delete(l.traces, l.order[l.pos])
+		l.order[l.pos] = id
+		l.pos = (l.pos + 1) % len(l.order)
+	}
+	l.traces[id] = &ItemTrace{
+		ID:     id,
+		Events: []LifecycleEvent{{Kind: EventEnqueued, At: time.Now()}},
+	}
+	l.mu.Unlock()
+
+	l.q.Enqueue(&lifecycleItem{id: id, v: v})
+	return id
+}
+
+// Dequeue 以消费者 consumer 的身份取出一个元素及其 ID。队列为空时 ok 为 false。
+func (l *LifecycleQueue) Dequeue(consumer string) (id uint64, v any, ok bool) {
+	item, _ := l.q.Dequeue().(*lifecycleItem)
+	if item == nil {
+		return 0, nil, false
+	}
+	l.record(item.id, EventDequeued, consumer)
+	return item.id, item.v, true
+}
+
+// Ack 记录消费者 consumer 已处理完 ID 为 id 的元素。
+func (l *LifecycleQueue) Ack(id uint64, consumer string) {
+	l.record(id, EventAcked, consumer)
+}
+
+// Trace 返回 ID 为 id 的元素的生命周期记录；记录不存在或已被淘汰时返回 false。
+func (l *LifecycleQueue) Trace(id uint64) (ItemTrace, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	t, ok := l.traces[id]
+	if !ok {
+		return ItemTrace{}, false
+	}
+	return ItemTrace{ID: t.ID, Events: append([]LifecycleEvent(nil), t.Events...)}, true
+}
+
+// Length returns the length of the underlying queue.
+func (l *LifecycleQueue) Length() uint64 {
+	return l.q.Length()
+}
+
+func (l *LifecycleQueue) record(id uint64, kind LifecycleEventKind, consumer string) {
+	l.mu.Lock()
+	if t, ok := l.traces[id]; ok {
+		t.Events = append(t.Events, LifecycleEvent{Kind: kind, At: time.Now(), Consumer: consumer})
+	}
+	l.mu.Unlock()
+}
@@ -0,0 +1,46 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestLifecycleQueue(t *testing.T) {
+	l := lockfree.NewLifecycleQueue(lockfree.NewQueue(), 2)
+	id := l.Enqueue("msg")
+
+	got, v, ok := l.Dequeue("worker-1")
+	if !ok || got != id || v != "msg" {
+		t.Fatalf("dequeue wrong, id=%d v=%v ok=%v", got, v, ok)
+	}
+	l.Ack(id, "worker-1")
+
+	trace, ok := l.Trace(id)
+	if !ok {
+		t.Fatalf("trace not found")
+	}
+	want := []lockfree.LifecycleEventKind{lockfree.EventEnqueued, lockfree.EventDequeued, lockfree.EventAcked}
+	if len(trace.Events) != len(want) {
+		t.Fatalf("want %d events, got %d", len(want), len(trace.Events))
+	}
+	for i, e := range trace.Events {
+		if e.Kind != want[i] {
+			t.Fatalf("event %d: want %v, got %v", i, want[i], e.Kind)
+		}
+	}
+	if trace.Events[1].Consumer != "worker-1" {
+		t.Fatalf("consumer not recorded")
+	}
+
+	if _, _, ok := l.Dequeue("worker-1"); ok {
+		t.Fatalf("dequeue empty queue returns ok")
+	}
+
+	// 超出保留上限后最旧的记录被淘汰
+	l.Enqueue("a")
+	l.Enqueue("b")
+	if _, ok := l.Trace(id); ok {
+		t.Fatalf("oldest trace should be evicted")
+	}
+}
@@ -0,0 +1,148 @@
+package lockfreequeue
+
+import "sync/atomic"
+
+// localCacheSize 是 Local 私有缓存中节点数的上限，缓存为空或超过上限时按其一半的数量与共享池批量交换。
+const localCacheSize = 64
+
+// localNode 是 Local 摘下后等待复用的节点，epoch 是摘下后读到的 epoch。
+type localNode struct {
+	i     *directItem
+	epoch uint64
+}
+
+// Local 是某个消费者（或同时入队和出队的工作 goroutine）私有的队列访问句柄。
+//
+// 在 ReclaimEpoch 方式下，Local 把出队时摘下的节点保存在私有缓存中，入队时优先复用，
+// 只在缓存为空或过满时才批量地与共享的 sync.Pool 交换节点，以减少对共享池的争用。
+// 摘下的节点要等 epoch 推进两次、确认没有其他操作还可能引用它之后才会被复用。
+// 其他回收方式下节点不经过私有缓存：ReclaimPool 下立即复用节点本身就不安全，ReclaimGC 下节点从不复用。
+//
+// Local 不是并发安全的，每个 goroutine 应使用自己的 Local；不再使用时调用 Flush。
+type Local struct {
+	q *Queue
+	// free 是可以立即复用的节点，pending 是等待 epoch 推进的节点，按 epoch 非递减排列
+	free    []*directItem
+	pending []localNode
+	retired uint32
+}
+
+// NewLocal 为 q 创建一个私有访问句柄。
+func (q *Queue) NewLocal() *Local {
+	return &Local{q: q}
+}
+
+// Enqueue 将 v 添加到队列末尾。
+func (l *Local) Enqueue(v any) {
+	l.q.enqueue(l.get(), v)
+}
+
+// Dequeue 从队列中移除并返回一个元素。队列为空时返回 nil。
+func (l *Local) Dequeue() any {
+	e := l.q.pin()
+	defer l.q.unpin(e)
+	for {
+		v, freed := l.q.dequeue()
+		if freed == nil {
+			return nil
+		}
+		l.put(freed)
+		if v, ok := unwrap(v); ok {
+			return v
+		}
+	}
+}
+
+// Flush 将私有缓存中的全部节点归还共享池，仍在等待 epoch 推进的节点交给队列的共享回收。
+func (l *Local) Flush() {
+	for _, i := range l.free {
+		l.q.pool.Put(i)
+	}
+	clear(l.free)
+	l.free = l.free[:0]
+	if len(l.pending) == 0 {
+		return
+	}
+	e := l.q.pin()
+	l.retire(len(l.pending))
+	l.q.unpin(e)
+}
+
+// get 返回一个用于入队的空节点。
+func (l *Local) get() *directItem {
+	if l.q.reclaim != ReclaimEpoch {
+		return l.q.newItem()
+	}
+	if len(l.free) == 0 {
+		l.promote()
+	}
+	if len(l.free) == 0 {
+		// 从共享池批量取出，之后的入队暂时不再访问共享池
+		for len(l.free) < localCacheSize/2 {
+			l.free = append(l.free, l.q.pool.Get().(*directItem))
+		}
+	}
+	i := l.free[len(l.free)-1]
+	l.free[len(l.free)-1] = nil
+	l.free = l.free[:len(l.free)-1]
+	trackGet(l.q, i)
+	return i
+}
+
+// put 回收一个已出队的节点，调用方必须处于 pin 状态。节点的 next 和 v 可能仍被其他 goroutine 读取，这里不能修改它们。
+func (l *Local) put(i *directItem) {
+	if l.q.reclaim != ReclaimEpoch {
+		l.q.free(i)
+		return
+	}
+	trackPut(l.q, i)
+	l.pending = append(l.pending, localNode{i: i, epoch: atomic.LoadUint64(&l.q.ep.epoch)})
+	// 私有缓存中的节点不计入共享的回收计数，由 Local 自己推动 epoch 前进
+	if l.retired++; l.retired%epochAdvanceEvery == 0 {
+		l.q.tryAdvance()
+	}
+	if len(l.pending) < localCacheSize {
+		return
+	}
+	l.promote()
+	if len(l.pending) >= localCacheSize {
+		// epoch 迟迟没有推进，把较早的一半交给共享回收
+		l.retire(localCacheSize / 2)
+	}
+	if len(l.free) > localCacheSize {
+		// 缓存过满，把一半归还共享池
+		n := len(l.free) - localCacheSize/2
+		for _, i := range l.free[n:] {
+			l.q.pool.Put(i)
+		}
+		clear(l.free[n:])
+		l.free = l.free[:n]
+	}
+}
+
+// promote 把 pending 中已经安全的节点移入 free：epoch 至少推进了两次的节点不会再被任何操作引用。
+func (l *Local) promote() {
+	e := atomic.LoadUint64(&l.q.ep.epoch)
+	n := 0
+	for n < len(l.pending) && l.pending[n].epoch+2 <= e {
+		l.free = append(l.free, l.pending[n].i)
+		n++
+	}
+	l.drop(n)
+}
+
+// retire 把 pending 中最早的 n 个节点交给队列的共享回收，调用方必须处于 pin 状态。
+// 共享回收以当前的 epoch 标记节点，不早于节点摘下时的 epoch，因此不会提前复用。
+func (l *Local) retire(n int) {
+	for _, p := range l.pending[:n] {
+		l.q.retire(p.i)
+	}
+	l.drop(n)
+}
+
+// drop 从 pending 中移除最早的 n 个节点。
+func (l *Local) drop(n int) {
+	m := copy(l.pending, l.pending[n:])
+	clear(l.pending[m:])
+	l.pending = l.pending[:m]
+}
@@ -0,0 +1,69 @@
+package lockfreequeue_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestLocal(t *testing.T) {
+	q := lockfree.NewQueue()
+	const producers, consumers, n = 4, 4, 10000
+
+	var wg sync.WaitGroup
+	for p := 0; p < producers; p++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := q.NewLocal()
+			defer l.Flush()
+			for i := 0; i < n; i++ {
+				l.Enqueue(i)
+			}
+		}()
+	}
+
+	var got int64
+	for c := 0; c < consumers; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			l := q.NewLocal()
+			defer l.Flush()
+			for atomic.LoadInt64(&got) < producers*n {
+				if l.Dequeue() != nil {
+					atomic.AddInt64(&got, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got != producers*n || q.Length() != 0 {
+		t.Fatalf("got %d items, length %d", got, q.Length())
+	}
+}
+
+func BenchmarkLocal(b *testing.B) {
+	q := lockfree.NewQueue()
+	v := new(int)
+	b.Run("Queue", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.Enqueue(v)
+				q.Dequeue()
+			}
+		})
+	})
+	b.Run("Local", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			l := q.NewLocal()
+			defer l.Flush()
+			for pb.Next() {
+				l.Enqueue(v)
+				l.Dequeue()
+			}
+		})
+	})
+}
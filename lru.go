@@ -0,0 +1,183 @@
+package lockfreequeue
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"unsafe"
+)
+
+// lruDead 标记一个已被淘汰的条目，之后对该条目的 Touch 需要重新创建条目。
+const lruDead = math.MaxUint64
+
+type lruEntry struct {
+	gen uint64
+}
+
+type lruToken[K comparable] struct {
+	key K
+	e   *lruEntry
+	gen uint64
+}
+
+// lruCompactSlack 是触发令牌压缩前允许的额外过期令牌数。
+const lruCompactSlack = 1024
+
+// lruState 是 LRU 当前使用的令牌队列，按从旧到新排列：
+// 较早的队列中的令牌都比较晚的队列中的令牌旧，Touch 只向最后一个队列追加令牌。
+// lruState 创建后不再修改，压缩时整体替换。
+type lruState struct {
+	qs []*Queue
+}
+
+// LRU 是一个并发的最近最少使用顺序结构，由无锁队列与索引组成。
+// Touch 把键移动到队尾，EvictOldest 从队头淘汰最久未被 Touch 的键。
+//
+// 移动是惰性的：每次 Touch 都会向队列追加一个携带代数的令牌，旧令牌不会被立即删除，
+// EvictOldest 遇到代数过期的令牌时直接丢弃。
+// 当令牌数超过 2*Len()+1024 时，触发的 Touch 会压缩令牌：先换上一个新的空队列接收之后的 Touch，
+// 再把旧队列中仍然有效的令牌按原顺序复制到一个新队列中，最后用它替换旧队列。
+// 压缩期间 Touch 与 EvictOldest 照常进行，不需要任何锁，令牌数（即内存占用）有界。
+type LRU[K comparable] struct {
+	state unsafe.Pointer // *lruState
+	index sync.Map       // K -> *lruEntry
+	n     int64
+
+	compacting int32
+}
+
+// NewLRU 创建并返回一个空的 LRU。
+func NewLRU[K comparable]() *LRU[K] {
+	return &LRU[K]{state: unsafe.Pointer(&lruState{qs: []*Queue{NewQueue()}})}
+}
+
+func (l *LRU[K]) load() *lruState {
+	return (*lruState)(atomic.LoadPointer(&l.state))
+}
+
+// Touch 将 key 标记为最近使用，键不存在时将其加入。
+func (l *LRU[K]) Touch(key K) {
+	l.touch(key)
+	if l.overfull() {
+		l.compact()
+	}
+}
+
+func (l *LRU[K]) touch(key K) {
+	for {
+		// 先 Load，键已存在时避免为 LoadOrStore 分配新的条目
+		v, loaded := l.index.Load(key)
+		if !loaded {
+			v, loaded = l.index.LoadOrStore(key, &lruEntry{})
+		}
+		e := v.(*lruEntry)
+		if !loaded {
+			atomic.AddInt64(&l.n, 1)
+		}
+		g := atomic.LoadUint64(&e.gen)
+		if g == lruDead {
+			// 条目刚被淘汰，帮助删除后重新创建
+			l.index.CompareAndDelete(key, e)
+			continue
+		}
+		if atomic.CompareAndSwapUint64(&e.gen, g, g+1) {
+			st := l.load()
+			q := st.qs[len(st.qs)-1]
+			q.Enqueue(&lruToken[K]{key: key, e: e, gen: g + 1})
+			// 压缩在复制之前关闭旧队列：入队后仍未关闭，说明复制一定能看到这个令牌；
+			// 否则令牌可能被遗漏，再 Touch 一次，把它放进新的队列
+			if !q.Closed() {
+				return
+			}
+		}
+	}
+}
+
+// tokens 返回全部队列中的令牌数，包括过期令牌。
+func (l *LRU[K]) tokens() uint64 {
+	var n uint64
+	for _, q := range l.load().qs {
+		n += q.Length()
+	}
+	return n
+}
+
+// overfull 报告过期令牌是否已经多到需要压缩。
+func (l *LRU[K]) overfull() bool {
+	n := atomic.LoadInt64(&l.n)
+	if n < 0 {
+		n = 0
+	}
+	return l.tokens() > 2*uint64(n)+lruCompactSlack
+}
+
+// compact 把仍然有效的令牌按原顺序复制到新队列，丢弃过期令牌。同一时间只有一个 goroutine 执行压缩。
+//
+// 复制期间旧队列仍留在 state 中，EvictOldest 照常从中淘汰：被淘汰的键的代数变为 lruDead，
+// 被重新 Touch 的键的代数增加，它们在新队列中的副本都会成为过期令牌，不会被重复淘汰。
+func (l *LRU[K]) compact() {
+	if !atomic.CompareAndSwapInt32(&l.compacting, 0, 1) {
+		return
+	}
+	defer atomic.StoreInt32(&l.compacting, 0)
+	if !l.overfull() {
+		return
+	}
+	old := l.load()
+	cur := NewQueue()
+	atomic.StorePointer(&l.state, unsafe.Pointer(&lruState{qs: append(old.qs[:len(old.qs):len(old.qs)], cur)}))
+	// 关闭之后，仍在向旧队列追加令牌的 Touch 会改为追加到 cur
+	old.qs[len(old.qs)-1].Close()
+
+	live := NewQueue()
+	for _, q := range old.qs {
+		q.Range(func(v any) bool {
+			if t := v.(*lruToken[K]); atomic.LoadUint64(&t.e.gen) == t.gen {
+				live.Enqueue(t)
+			}
+			return true
+		})
+	}
+	atomic.StorePointer(&l.state, unsafe.Pointer(&lruState{qs: []*Queue{live, cur}}))
+}
+
+// EvictOldest 淘汰并返回最久未被 Touch 的键。LRU 为空时 ok 为 false。
+func (l *LRU[K]) EvictOldest() (key K, ok bool) {
+	for _, q := range l.load().qs {
+		for v := q.Dequeue(); v != nil; v = q.Dequeue() {
+			t := v.(*lruToken[K])
+			// 只有代数仍为最新的令牌才代表该键当前的位置
+			if atomic.CompareAndSwapUint64(&t.e.gen, t.gen, lruDead) {
+				l.index.CompareAndDelete(t.key, t.e)
+				atomic.AddInt64(&l.n, -1)
+				return t.key, true
+			}
+		}
+	}
+	return key, false
+}
+
+// Remove 删除 key，返回键是否存在。
+func (l *LRU[K]) Remove(key K) bool {
+	v, ok := l.index.Load(key)
+	if !ok {
+		return false
+	}
+	e := v.(*lruEntry)
+	for {
+		g := atomic.LoadUint64(&e.gen)
+		if g == lruDead {
+			return false
+		}
+		if atomic.CompareAndSwapUint64(&e.gen, g, lruDead) {
+			l.index.CompareAndDelete(key, e)
+			atomic.AddInt64(&l.n, -1)
+			return true
+		}
+	}
+}
+
+// Len 返回 LRU 中的键数量。
+func (l *LRU[K]) Len() int {
+	return int(atomic.LoadInt64(&l.n))
+}
@@ -0,0 +1,58 @@
+package lockfreequeue
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLRUCompaction(t *testing.T) {
+	l := NewLRU[int]()
+	for i := 0; i < 100000; i++ {
+		l.Touch(i % 10)
+	}
+	if n := l.tokens(); n > 2*10+lruCompactSlack+1 {
+		t.Fatalf("stale tokens must be compacted, have %d", n)
+	}
+	// 压缩不能打乱顺序：最后一轮 Touch 的顺序就是淘汰顺序
+	for want := 0; want < 10; want++ {
+		if key, ok := l.EvictOldest(); !ok || key != want {
+			t.Fatalf("want %d, got %d (ok=%v)", want, key, ok)
+		}
+	}
+}
+
+func TestLRUConcurrentCompaction(t *testing.T) {
+	l := NewLRU[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 20000; i++ {
+				l.Touch(i % 50)
+				if g == 0 && i%7 == 0 {
+					l.EvictOldest()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+	if n := l.tokens(); n > 2*50+lruCompactSlack+1 {
+		t.Fatalf("stale tokens must be compacted, have %d", n)
+	}
+	// 压缩期间的 Touch 不能丢失：每个仍在 LRU 中的键都必须能被淘汰，且只淘汰一次
+	n, seen := l.Len(), make(map[int]bool)
+	for {
+		key, ok := l.EvictOldest()
+		if !ok {
+			break
+		}
+		if seen[key] {
+			t.Fatalf("key %d evicted twice", key)
+		}
+		seen[key] = true
+	}
+	if len(seen) != n || l.Len() != 0 {
+		t.Fatalf("want %d keys evicted, got %d (len %d)", n, len(seen), l.Len())
+	}
+}
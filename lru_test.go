@@ -0,0 +1,71 @@
+package lockfreequeue_test
+
+import (
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestLRU(t *testing.T) {
+	l := lockfree.NewLRU[string]()
+	l.Touch("a")
+	l.Touch("b")
+	l.Touch("c")
+	l.Touch("a")
+	if l.Len() != 3 {
+		t.Fatalf("want 3 keys, got %d", l.Len())
+	}
+
+	if !l.Remove("c") || l.Remove("c") {
+		t.Fatalf("remove wrong")
+	}
+	for _, want := range []string{"b", "a"} {
+		key, ok := l.EvictOldest()
+		if !ok || key != want {
+			t.Fatalf("want %s, got %s", want, key)
+		}
+	}
+	if _, ok := l.EvictOldest(); ok || l.Len() != 0 {
+		t.Fatalf("lru should be empty")
+	}
+
+	// 淘汰之后可以重新加入
+	l.Touch("a")
+	if key, ok := l.EvictOldest(); !ok || key != "a" {
+		t.Fatalf("re-added key not evicted")
+	}
+}
+
+func TestLRUConcurrent(t *testing.T) {
+	l := lockfree.NewLRU[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				l.Touch(i % 100)
+				if i%3 == 0 {
+					l.EvictOldest()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	seen := make(map[int]bool)
+	for {
+		key, ok := l.EvictOldest()
+		if !ok {
+			break
+		}
+		if seen[key] {
+			t.Fatalf("key %d evicted twice", key)
+		}
+		seen[key] = true
+	}
+	if l.Len() != 0 {
+		t.Fatalf("length should be zero after draining, got %d", l.Len())
+	}
+}
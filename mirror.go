@@ -0,0 +1,81 @@
+package lockfreequeue
+
+import "sync/atomic"
+
+// DropPolicy 决定有界队列已满时如何处理新元素。
+type DropPolicy int
+
+const (
+	// DropNewest 丢弃新到达的元素。
+	DropNewest DropPolicy = iota
+	// DropOldest 丢弃队头最旧的元素，为新元素腾出位置。
+	DropOldest
+)
+
+// MirrorQueue 将每个入队元素同时复制到一个影子队列，
+// 便于金丝雀版本的消费者并行处理真实流量而不影响主路径。
+// 影子队列有独立的上限与丢弃策略，主队列永远不会因为影子队列而丢弃或阻塞。
+type MirrorQueue struct {
+	primary *Queue
+	shadow  *Queue
+	limit   uint64
+	policy  DropPolicy
+	dropped uint64
+}
+
+// NewMirrorQueue 创建一个主队列为 primary、影子队列为 shadow 的 MirrorQueue。
+// limit 为影子队列的最大长度，0 表示不限制；并发入队时该上限是近似的。
+func NewMirrorQueue(primary, shadow *Queue, limit uint64, policy DropPolicy) *MirrorQueue {
+	return &MirrorQueue{
+		primary: primary,
+		shadow:  shadow,
+		limit:   limit,
+		policy:  policy,
+	}
+}
+
+// Enqueue 将 v 添加到主队列，并按影子队列的丢弃策略复制到影子队列。
+func (m *MirrorQueue) Enqueue(v any) {
+	m.primary.Enqueue(v)
+	if m.limit > 0 && m.shadow.Length() >= m.limit {
+		if m.policy == DropNewest {
+			atomic.AddUint64(&m.dropped, 1)
+			return
+		}
+		if m.shadow.Dequeue() != nil {
+			atomic.AddUint64(&m.dropped, 1)
+		}
+	}
+	m.shadow.Enqueue(v)
+}
+
+// Dequeue 从主队列中移除并返回一个元素。队列为空时返回 nil。
+func (m *MirrorQueue) Dequeue() any {
+	return m.primary.Dequeue()
+}
+
+// Length returns the length of the primary queue.
+func (m *MirrorQueue) Length() uint64 {
+	return m.primary.Length()
+}
+
+// Len returns the length of the primary queue as an int.
+func (m *MirrorQueue) Len() int {
+	return m.primary.Len()
+}
+
+// Close 关闭主队列与影子队列，返回关闭主队列的错误。
+func (m *MirrorQueue) Close() error {
+	m.shadow.Close()
+	return m.primary.Close()
+}
+
+// Shadow 返回影子队列，供金丝雀消费者读取。
+func (m *MirrorQueue) Shadow() *Queue {
+	return m.shadow
+}
+
+// Dropped 返回影子队列因达到上限而丢弃的元素个数。
+func (m *MirrorQueue) Dropped() uint64 {
+	return atomic.LoadUint64(&m.dropped)
+}
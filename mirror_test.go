@@ -0,0 +1,34 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestMirrorQueue(t *testing.T) {
+	for _, tc := range []struct {
+		policy lockfree.DropPolicy
+		head   int
+	}{
+		{lockfree.DropNewest, 0},
+		{lockfree.DropOldest, 3},
+	} {
+		m := lockfree.NewMirrorQueue(lockfree.NewQueue(), lockfree.NewQueue(), 2, tc.policy)
+		for i := 0; i < 5; i++ {
+			m.Enqueue(i)
+		}
+		if m.Length() != 5 {
+			t.Fatalf("primary must receive every item, length %d", m.Length())
+		}
+		if m.Shadow().Length() != 2 || m.Dropped() != 3 {
+			t.Fatalf("policy %d: shadow length %d, dropped %d", tc.policy, m.Shadow().Length(), m.Dropped())
+		}
+		if v := m.Shadow().Dequeue(); v != tc.head {
+			t.Fatalf("policy %d: want shadow head %d, got %v", tc.policy, tc.head, v)
+		}
+		if v := m.Dequeue(); v != 0 {
+			t.Fatalf("primary order wrong, got %v", v)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+//go:build !race
+
+package lockfreequeue_test
+
+const raceEnabled = false
@@ -0,0 +1,146 @@
+package lockfreequeue
+
+import (
+	"context"
+	"database/sql"
+	"sync/atomic"
+	"time"
+)
+
+// OutboxScanner 从当前行中读取行的单调递增 ID 以及要入队的值。
+type OutboxScanner func(rows *sql.Rows) (id int64, v any, err error)
+
+// OutboxPoller 轮询一张 outbox 表，将新写入的行入队到本地队列。
+// 查询语句由调用方提供，必须接受一个参数（当前水位线），并按 ID 升序返回 ID 大于水位线的行，
+// 例如 "SELECT id, payload FROM outbox WHERE id > ? ORDER BY id LIMIT 100"。
+//
+// 水位线只在行入队之后才前移，并在前移前调用 Commit 持久化；
+// 进程崩溃或 Commit 失败时，尚未确认的行会在下一次轮询中被重新读取，即至少一次（at-least-once）语义。
+type OutboxPoller struct {
+	// Interval 是两次轮询之间的间隔，为 0 时使用 1 秒。
+	Interval time.Duration
+	// Limit 大于 0 时，队列长度达到 Limit 后暂停入队，形成背压。
+	Limit uint64
+	// Commit 在水位线前移之前被调用，用于把水位线持久化，可以为 nil。
+	Commit func(ctx context.Context, watermark int64) error
+
+	db        *sql.DB
+	q         *Queue
+	query     string
+	scan      OutboxScanner
+	watermark int64
+}
+
+// NewOutboxPoller 创建一个从 watermark 之后开始轮询的 OutboxPoller。
+func NewOutboxPoller(db *sql.DB, q *Queue, query string, scan OutboxScanner, watermark int64) *OutboxPoller {
+	return &OutboxPoller{
+		db:        db,
+		q:         q,
+		query:     query,
+		scan:      scan,
+		watermark: watermark,
+	}
+}
+
+// Watermark 返回已入队并确认的最大行 ID。
+func (p *OutboxPoller) Watermark() int64 {
+	return atomic.LoadInt64(&p.watermark)
+}
+
+// Run 按 Interval 持续轮询，直到 ctx 结束或某次轮询出错。
+func (p *OutboxPoller) Run(ctx context.Context) error {
+	interval := p.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		if _, err := p.Poll(ctx); err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctxErr(ctx)
+		case <-t.C:
+		}
+	}
+}
+
+// Poll 执行一次查询，将返回的行全部入队并前移水位线。
+// 查询结果会先被完整读出并关闭，然后才进行背压等待和入队，避免在等待消费者期间占用数据库连接。
+// 返回值:
+//
+//	int   - 本次入队的行数。
+//	error - 查询、扫描、背压等待或 Commit 的错误。
+func (p *OutboxPoller) Poll(ctx context.Context) (int, error) {
+	mark := p.Watermark()
+	batch, scanErr := p.fetch(ctx, mark)
+
+	n := 0
+	for _, r := range batch {
+		if err := waitBelow(ctx, p.q, p.Limit); err != nil {
+			return n, p.advance(ctx, mark, err)
+		}
+		p.q.Enqueue(r.v)
+		n++
+		if r.id > mark {
+			mark = r.id
+		}
+	}
+	return n, p.advance(ctx, mark, scanErr)
+}
+
+// outboxRow 是从查询结果中读出、尚未入队的一行。
+type outboxRow struct {
+	id int64
+	v  any
+}
+
+// fetch 执行查询并读出全部行，返回前关闭结果集。
+// 扫描出错时返回出错之前读到的行以及该错误。
+func (p *OutboxPoller) fetch(ctx context.Context, mark int64) ([]outboxRow, error) {
+	rows, err := p.db.QueryContext(ctx, p.query, mark)
+	if err != nil {
+		return nil, queryErr(ctx, err)
+	}
+	defer rows.Close()
+
+	var batch []outboxRow
+	for rows.Next() {
+		id, v, err := p.scan(rows)
+		if err != nil {
+			return batch, err
+		}
+		batch = append(batch, outboxRow{id: id, v: v})
+	}
+	return batch, queryErr(ctx, rows.Err())
+}
+
+// queryErr 在 ctx 已结束时把数据库返回的错误统一转换为 ErrCancelled 或 ErrTimeout。
+func queryErr(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+	if cerr := ctxErr(ctx); cerr != nil {
+		return cerr
+	}
+	return err
+}
+
+// advance 确认已入队的行，并返回 cause 或 Commit 的错误（cause 优先）。
+func (p *OutboxPoller) advance(ctx context.Context, mark int64, cause error) error {
+	if mark == p.Watermark() {
+		return cause
+	}
+	if p.Commit != nil {
+		if err := p.Commit(ctx, mark); err != nil {
+			if cause != nil {
+				return cause
+			}
+			return err
+		}
+	}
+	atomic.StoreInt64(&p.watermark, mark)
+	return cause
+}
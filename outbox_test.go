@@ -0,0 +1,216 @@
+package lockfreequeue_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+// outboxDriver 是一个只支持 "id > ?" 查询的内存 outbox 表。
+type outboxDriver struct {
+	mu      sync.Mutex
+	rows    [][2]driver.Value
+	queries int
+	open    int
+}
+
+func (d *outboxDriver) Open(string) (driver.Conn, error) { return outboxConn{d}, nil }
+
+type outboxConn struct{ d *outboxDriver }
+
+func (c outboxConn) Prepare(string) (driver.Stmt, error) { return outboxStmt(c), nil }
+func (c outboxConn) Close() error                        { return nil }
+func (c outboxConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+type outboxStmt struct{ d *outboxDriver }
+
+func (s outboxStmt) Close() error  { return nil }
+func (s outboxStmt) NumInput() int { return 1 }
+func (s outboxStmt) Exec([]driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+
+func (s outboxStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.mu.Lock()
+	defer s.d.mu.Unlock()
+	mark := args[0].(int64)
+	s.d.queries++
+	s.d.open++
+	r := &outboxRows{d: s.d}
+	for _, row := range s.d.rows {
+		if row[0].(int64) > mark {
+			r.rows = append(r.rows, row)
+		}
+	}
+	return r, nil
+}
+
+type outboxRows struct {
+	d    *outboxDriver
+	rows [][2]driver.Value
+}
+
+func (r *outboxRows) Columns() []string { return []string{"id", "payload"} }
+func (r *outboxRows) Close() error {
+	r.d.mu.Lock()
+	defer r.d.mu.Unlock()
+	r.d.open--
+	return nil
+}
+func (r *outboxRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	dest[0], dest[1] = r.rows[0][0], r.rows[0][1]
+	r.rows = r.rows[1:]
+	return nil
+}
+
+var outbox = &outboxDriver{}
+
+func init() {
+	sql.Register("lockfreequeue-outbox", outbox)
+}
+
+func scanOutbox(rows *sql.Rows) (int64, any, error) {
+	var id int64
+	var payload string
+	err := rows.Scan(&id, &payload)
+	return id, payload, err
+}
+
+func TestOutboxPoller(t *testing.T) {
+	db, err := sql.Open("lockfreequeue-outbox", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	outbox.mu.Lock()
+	outbox.rows = [][2]driver.Value{{int64(1), "a"}, {int64(2), "b"}}
+	outbox.mu.Unlock()
+
+	q := lockfree.NewQueue()
+	var committed int64
+	p := lockfree.NewOutboxPoller(db, q, "SELECT id, payload FROM outbox WHERE id > ?", scanOutbox, 0)
+	p.Commit = func(_ context.Context, watermark int64) error {
+		committed = watermark
+		return nil
+	}
+
+	n, err := p.Poll(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 || p.Watermark() != 2 || committed != 2 {
+		t.Fatalf("poll wrong, n=%d watermark=%d committed=%d", n, p.Watermark(), committed)
+	}
+
+	outbox.mu.Lock()
+	outbox.rows = append(outbox.rows, [2]driver.Value{int64(3), "c"})
+	outbox.mu.Unlock()
+
+	if n, _ := p.Poll(context.Background()); n != 1 {
+		t.Fatalf("second poll should only see new rows, got %d", n)
+	}
+	for _, want := range []string{"a", "b", "c"} {
+		if v := q.Dequeue(); v != want {
+			t.Fatalf("want %v, got %v", want, v)
+		}
+	}
+}
+
+func TestOutboxPollerCommitFailure(t *testing.T) {
+	db, err := sql.Open("lockfreequeue-outbox", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	outbox.mu.Lock()
+	outbox.rows = [][2]driver.Value{{int64(1), "a"}}
+	outbox.mu.Unlock()
+
+	q := lockfree.NewQueue()
+	p := lockfree.NewOutboxPoller(db, q, "SELECT id, payload FROM outbox WHERE id > ?", scanOutbox, 0)
+	p.Commit = func(context.Context, int64) error { return errors.New("commit failed") }
+
+	if _, err := p.Poll(context.Background()); err == nil {
+		t.Fatalf("expected commit error")
+	}
+	if p.Watermark() != 0 {
+		t.Fatalf("watermark must not advance on failed commit, got %d", p.Watermark())
+	}
+	// 未确认的行会被重新投递
+	p.Commit = nil
+	if n, _ := p.Poll(context.Background()); n != 1 {
+		t.Fatalf("unacknowledged row should be redelivered, got %d", n)
+	}
+	if q.Length() != 2 {
+		t.Fatalf("at-least-once delivery expects duplicates, got length %d", q.Length())
+	}
+}
+
+func TestOutboxPollerBackpressureClosesRows(t *testing.T) {
+	db, err := sql.Open("lockfreequeue-outbox", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	outbox.mu.Lock()
+	outbox.rows = [][2]driver.Value{{int64(1), "a"}, {int64(2), "b"}}
+	outbox.queries = 0
+	outbox.mu.Unlock()
+
+	q := lockfree.NewQueue()
+	q.Enqueue("x")
+	p := lockfree.NewOutboxPoller(db, q, "SELECT id, payload FROM outbox WHERE id > ?", scanOutbox, 0)
+	p.Limit = 1
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := p.Poll(context.Background())
+		done <- err
+	}()
+	// 等待背压期间结果集必须已经关闭
+	waitFor(t, func() bool {
+		outbox.mu.Lock()
+		defer outbox.mu.Unlock()
+		return outbox.queries == 1 && outbox.open == 0
+	})
+	for _, want := range []string{"x", "a", "b"} {
+		var v any
+		waitFor(t, func() bool {
+			v = q.Dequeue()
+			return v != nil
+		})
+		if v != want {
+			t.Fatalf("want %v, got %v", want, v)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestOutboxPollerCancelled(t *testing.T) {
+	db, err := sql.Open("lockfreequeue-outbox", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	p := lockfree.NewOutboxPoller(db, lockfree.NewQueue(), "SELECT id, payload FROM outbox WHERE id > ?", scanOutbox, 0)
+	if _, err := p.Poll(ctx); !errors.Is(err, lockfree.ErrCancelled) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled error, got %v", err)
+	}
+}
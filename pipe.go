@@ -0,0 +1,237 @@
+package lockfreequeue
+
+import (
+	"io"
+	"sync"
+	"sync/atomic"
+)
+
+// QueuePipe 是一个以 ByteQueue 为缓冲的内存管道，用法与 io.Pipe 相同：
+// 一端 Write，另一端 Read，写端关闭后读端读完缓冲的数据即返回 io.EOF。
+//
+// 与 io.Pipe 不同，Write 不等待读端取走数据，而是把数据复制进最多 limit 字节的缓冲后立即返回；
+// 缓冲已满时 Write 阻塞，形成背压，TryWrite 则只写入能放下的部分而不阻塞。
+// Buffered 与 Chunks 给出当前在途的字节数与分块数，便于观察代理中的积压。
+//
+// 多个 goroutine 并发 Write 时，每次 Write 的数据保持连续；并发 Read 也是安全的。
+type QueuePipe struct {
+	q        *ByteQueue
+	limit    int64
+	buffered int64
+
+	wmu sync.Mutex // 串行化写端
+	rmu sync.Mutex // 串行化读端，并保护 cur 与 off
+	cur *Buffer
+	off int
+
+	// readable 与 writable 是容量为 1 的唤醒信号，分别在入队与释放缓冲后发送
+	readable chan struct{}
+	writable chan struct{}
+
+	wonce sync.Once
+	ronce sync.Once
+	wdone chan struct{} // 写端关闭时关闭
+	rdone chan struct{} // 读端关闭时关闭
+	werr  error
+	rerr  error
+}
+
+// NewQueuePipe 创建一个最多缓冲 limit 字节的 QueuePipe。limit 不大于 0 时缓冲不受限制，Write 永不阻塞。
+func NewQueuePipe(limit int) *QueuePipe {
+	return &QueuePipe{
+		q:        NewByteQueue(),
+		limit:    int64(limit),
+		readable: make(chan struct{}, 1),
+		writable: make(chan struct{}, 1),
+		wdone:    make(chan struct{}),
+		rdone:    make(chan struct{}),
+	}
+}
+
+// Write 实现 io.Writer。p 会被复制，调用返回后可以复用。
+// 缓冲空间不足时阻塞直到读端释放空间；读端已关闭时返回其错误，写端已关闭时返回 io.ErrClosedPipe。
+// 单次写入超过 limit 时会被拆分，读端可能分多次读到。
+func (p *QueuePipe) Write(b []byte) (int, error) {
+	p.wmu.Lock()
+	defer p.wmu.Unlock()
+	n := 0
+	for n < len(b) {
+		if err := p.writeErr(); err != nil {
+			return n, err
+		}
+		room := p.room()
+		if room == 0 {
+			select {
+			case <-p.writable:
+			case <-p.rdone:
+			case <-p.wdone:
+			}
+			continue
+		}
+		n += p.push(b[n:], room)
+	}
+	return n, nil
+}
+
+// TryWrite 不阻塞地写入 b 中缓冲放得下的部分。
+// 返回值:
+//
+//	int   - 写入的字节数。
+//	error - 未能全部写入时返回 ErrFull；管道关闭时返回与 Write 相同的错误。
+func (p *QueuePipe) TryWrite(b []byte) (int, error) {
+	if !p.wmu.TryLock() {
+		// 另一个 Write 正在等待空间
+		return 0, ErrFull
+	}
+	defer p.wmu.Unlock()
+	if err := p.writeErr(); err != nil {
+		return 0, err
+	}
+	n := 0
+	if room := p.room(); room != 0 && len(b) > 0 {
+		n = p.push(b, room)
+	}
+	if n < len(b) {
+		return n, ErrFull
+	}
+	return n, nil
+}
+
+// Read 实现 io.Reader。缓冲为空时阻塞直到有数据写入或写端关闭；
+// 写端关闭且缓冲读完后返回写端的关闭错误（默认为 io.EOF），读端已关闭时返回 io.ErrClosedPipe。
+func (p *QueuePipe) Read(b []byte) (int, error) {
+	p.rmu.Lock()
+	defer p.rmu.Unlock()
+	if len(b) == 0 {
+		return 0, nil
+	}
+	for p.cur == nil {
+		select {
+		case <-p.rdone:
+			return 0, io.ErrClosedPipe
+		default:
+		}
+		if p.cur = p.q.Dequeue(); p.cur != nil {
+			break
+		}
+		select {
+		case <-p.wdone:
+			// 关闭前写入的数据可能刚刚入队，再检查一次
+			if p.cur = p.q.Dequeue(); p.cur == nil {
+				return 0, p.werr
+			}
+		case <-p.readable:
+		case <-p.rdone:
+		}
+	}
+	n := copy(b, p.cur.B[p.off:])
+	p.off += n
+	if p.off == len(p.cur.B) {
+		p.release()
+	}
+	return n, nil
+}
+
+// Close 关闭写端，等价于 CloseWithError(nil)。
+func (p *QueuePipe) Close() error {
+	return p.CloseWithError(nil)
+}
+
+// CloseWithError 关闭写端，读端读完缓冲的数据后返回 err；err 为 nil 时返回 io.EOF。
+// 只有第一次关闭生效。
+func (p *QueuePipe) CloseWithError(err error) error {
+	if err == nil {
+		err = io.EOF
+	}
+	p.wonce.Do(func() {
+		p.werr = err
+		close(p.wdone)
+	})
+	return nil
+}
+
+// CloseRead 关闭读端并丢弃缓冲的数据，之后的 Write 返回 err；err 为 nil 时返回 io.ErrClosedPipe。
+// 只有第一次关闭生效。
+func (p *QueuePipe) CloseRead(err error) error {
+	if err == nil {
+		err = io.ErrClosedPipe
+	}
+	p.ronce.Do(func() {
+		p.rerr = err
+		close(p.rdone)
+	})
+	p.rmu.Lock()
+	defer p.rmu.Unlock()
+	if p.cur != nil {
+		p.release()
+	}
+	for b := p.q.Dequeue(); b != nil; b = p.q.Dequeue() {
+		atomic.AddInt64(&p.buffered, -int64(len(b.B)))
+		b.Release()
+	}
+	return nil
+}
+
+// Buffered 返回已写入但尚未被读取的字节数。
+func (p *QueuePipe) Buffered() int {
+	return int(atomic.LoadInt64(&p.buffered))
+}
+
+// Chunks 返回缓冲中的分块数，即底层 ByteQueue 的长度。
+func (p *QueuePipe) Chunks() uint64 {
+	return p.q.Length()
+}
+
+// writeErr 返回写端当前应当报告的错误。
+func (p *QueuePipe) writeErr() error {
+	select {
+	case <-p.rdone:
+		return p.rerr
+	default:
+	}
+	select {
+	case <-p.wdone:
+		return io.ErrClosedPipe
+	default:
+	}
+	return nil
+}
+
+// room 返回缓冲中剩余的字节数，不限制时返回 -1。
+func (p *QueuePipe) room() int64 {
+	if p.limit <= 0 {
+		return -1
+	}
+	if room := p.limit - atomic.LoadInt64(&p.buffered); room > 0 {
+		return room
+	}
+	return 0
+}
+
+// push 复制 b 中至多 room 字节（room 为 -1 时不限制）入队并唤醒读端，返回复制的字节数。
+func (p *QueuePipe) push(b []byte, room int64) int {
+	if room >= 0 && int64(len(b)) > room {
+		b = b[:room]
+	}
+	// 先计入缓冲再入队，读端释放时不会使计数变为负数
+	atomic.AddInt64(&p.buffered, int64(len(b)))
+	p.q.Enqueue(append([]byte(nil), b...), nil)
+	notify(p.readable)
+	return len(b)
+}
+
+// release 释放读完的当前分块并唤醒写端，调用方必须持有 p.rmu。
+func (p *QueuePipe) release() {
+	atomic.AddInt64(&p.buffered, -int64(len(p.cur.B)))
+	p.cur.Release()
+	p.cur, p.off = nil, 0
+	notify(p.writable)
+}
+
+// notify 向容量为 1 的信号 channel 发送一次唤醒，已有未消费的唤醒时不阻塞。
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,94 @@
+package lockfreequeue_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestQueuePipe(t *testing.T) {
+	p := lockfree.NewQueuePipe(16)
+	data := bytes.Repeat([]byte("0123456789"), 100)
+
+	done := make(chan error, 1)
+	go func() {
+		for i := 0; i < len(data); i += 7 {
+			if _, err := p.Write(data[i:min(i+7, len(data))]); err != nil {
+				done <- err
+				return
+			}
+			if n := p.Buffered(); n > 16 {
+				done <- errors.New("buffered exceeds limit")
+				return
+			}
+		}
+		done <- p.Close()
+	}()
+
+	got, err := io.ReadAll(p)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("writer failed: %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("data corrupted: got %d bytes", len(got))
+	}
+	if p.Buffered() != 0 || p.Chunks() != 0 {
+		t.Fatalf("buffer not empty: %d bytes, %d chunks", p.Buffered(), p.Chunks())
+	}
+}
+
+func TestQueuePipeTryWrite(t *testing.T) {
+	p := lockfree.NewQueuePipe(4)
+	if n, err := p.TryWrite([]byte("abcdef")); n != 4 || !errors.Is(err, lockfree.ErrFull) {
+		t.Fatalf("want partial write with ErrFull, n=%d err=%v", n, err)
+	}
+	if n, err := p.TryWrite([]byte("x")); n != 0 || !errors.Is(err, lockfree.ErrFull) {
+		t.Fatalf("full pipe must reject, n=%d err=%v", n, err)
+	}
+	buf := make([]byte, 8)
+	if n, _ := p.Read(buf); string(buf[:n]) != "abcd" {
+		t.Fatalf("unexpected read %q", buf[:n])
+	}
+	if n, err := p.TryWrite([]byte("ef")); n != 2 || err != nil {
+		t.Fatalf("write after drain failed, n=%d err=%v", n, err)
+	}
+}
+
+func TestQueuePipeClose(t *testing.T) {
+	p := lockfree.NewQueuePipe(0)
+	boom := errors.New("boom")
+	if n, err := p.TryWrite([]byte("ta")); n != 2 || err != nil {
+		t.Fatalf("unbounded pipe must accept, n=%d err=%v", n, err)
+	}
+	p.Write([]byte("il"))
+	p.CloseWithError(boom)
+	if _, err := p.Write([]byte("x")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("write after close: %v", err)
+	}
+	got, err := io.ReadAll(p)
+	if string(got) != "tail" || !errors.Is(err, boom) {
+		t.Fatalf("reader must drain then see the close error, got %q, %v", got, err)
+	}
+
+	p = lockfree.NewQueuePipe(4)
+	p.Write([]byte("abcd"))
+	done := make(chan error, 1)
+	go func() {
+		// 缓冲已满，Write 阻塞直到读端关闭
+		_, err := p.Write([]byte("e"))
+		done <- err
+	}()
+	p.CloseRead(nil)
+	if err := <-done; !errors.Is(err, io.ErrClosedPipe) {
+		t.Fatalf("blocked write must fail once the reader closes, got %v", err)
+	}
+	if p.Buffered() != 0 {
+		t.Fatalf("closing the reader must discard buffered data, %d bytes left", p.Buffered())
+	}
+}
@@ -0,0 +1,95 @@
+package lockfreequeue
+
+import (
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Producer 是某个生产者私有的入队缓冲区：元素先在本地链接成一条链，
+// 每攒够 maxItems 个或距第一个缓冲元素超过 maxDelay 时，用一次尾部 CAS 把整条链追加到队列，
+// 以有界的额外延迟换取更高的总吞吐量。
+//
+// 缓冲中的元素对消费者不可见，也不计入队列的 Length。
+// Producer 可以被多个 goroutine 使用，但设计上每个生产者应拥有自己的 Producer；不再使用时调用 Close。
+// Close 之后的 Enqueue 不再缓冲，而是直接入队。
+type Producer struct {
+	q        *Queue
+	maxItems uint64
+	maxDelay time.Duration
+
+	mu          sync.Mutex
+	first, last *directItem
+	n           uint64
+	timer       *time.Timer
+	closed      bool
+}
+
+// NewProducer 为 q 创建一个缓冲生产者。maxItems 小于 1 时视为 1；maxDelay 为 0 时只按个数刷新。
+func (q *Queue) NewProducer(maxItems int, maxDelay time.Duration) *Producer {
+	if maxItems < 1 {
+		maxItems = 1
+	}
+	return &Producer{q: q, maxItems: uint64(maxItems), maxDelay: maxDelay}
+}
+
+// Enqueue 将 v 加入本地缓冲，缓冲满时立即刷新。Producer 已关闭时 v 直接入队。
+func (p *Producer) Enqueue(v any) {
+	i := p.q.newItem()
+	i.next, i.v = nil, v
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		// 关闭后没有定时器负责刷新，缓冲的元素会滞留，因此同步入队
+		p.q.enqueueChain(i, i, 1)
+		return
+	}
+	if p.first == nil {
+		p.first = i
+		if p.maxDelay > 0 {
+			if p.timer == nil {
+				p.timer = time.AfterFunc(p.maxDelay, p.Flush)
+			} else {
+				p.timer.Reset(p.maxDelay)
+			}
+		}
+	} else {
+		p.last.next = unsafe.Pointer(i)
+	}
+	p.last = i
+	p.n++
+	if p.n >= p.maxItems {
+		p.flush()
+	}
+}
+
+// Flush 立即把缓冲的元素追加到队列。
+func (p *Producer) Flush() {
+	p.mu.Lock()
+	p.flush()
+	p.mu.Unlock()
+}
+
+// Close 刷新剩余的元素并停止定时器。
+func (p *Producer) Close() {
+	p.mu.Lock()
+	p.closed = true
+	p.flush()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.mu.Unlock()
+}
+
+// flush 调用方必须持有 p.mu。
+func (p *Producer) flush() {
+	if p.first == nil {
+		return
+	}
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.q.enqueueChain(p.first, p.last, p.n)
+	p.first, p.last, p.n = nil, nil, 0
+}
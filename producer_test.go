@@ -0,0 +1,104 @@
+package lockfreequeue_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestProducerFlushBySize(t *testing.T) {
+	q := lockfree.NewQueue()
+	p := q.NewProducer(3, 0)
+	p.Enqueue(1)
+	p.Enqueue(2)
+	if q.Length() != 0 {
+		t.Fatalf("buffered items visible before flush")
+	}
+	p.Enqueue(3)
+	if q.Length() != 3 {
+		t.Fatalf("want 3 items after flush, got %d", q.Length())
+	}
+	for want := 1; want <= 3; want++ {
+		if v := q.Dequeue(); v != want {
+			t.Fatalf("want %d, got %v", want, v)
+		}
+	}
+}
+
+func TestProducerEnqueueAfterClose(t *testing.T) {
+	q := lockfree.NewQueue()
+	p := q.NewProducer(100, time.Hour)
+	p.Enqueue(1)
+	p.Close()
+	p.Enqueue(2)
+	if q.Length() != 2 {
+		t.Fatalf("enqueue after close must not be buffered, length %d", q.Length())
+	}
+}
+
+func TestProducerFlushByTime(t *testing.T) {
+	q := lockfree.NewQueue()
+	p := q.NewProducer(100, time.Millisecond)
+	defer p.Close()
+	p.Enqueue("x")
+	waitFor(t, func() bool { return q.Length() == 1 })
+}
+
+func TestProducerConcurrent(t *testing.T) {
+	q := lockfree.NewQueue()
+	const producers, n = 4, 10000
+	var wg sync.WaitGroup
+	for g := 0; g < producers; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			p := q.NewProducer(16, 50*time.Microsecond)
+			defer p.Close()
+			for i := 0; i < n; i++ {
+				p.Enqueue(g*n + i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	last := make([]int, producers)
+	for i := range last {
+		last[i] = -1
+	}
+	got := 0
+	for v := q.Dequeue(); v != nil; v = q.Dequeue() {
+		g, i := v.(int)/n, v.(int)%n
+		if i <= last[g] {
+			t.Fatalf("producer %d out of order", g)
+		}
+		last[g] = i
+		got++
+	}
+	if got != producers*n {
+		t.Fatalf("want %d items, got %d", producers*n, got)
+	}
+}
+
+func BenchmarkProducer(b *testing.B) {
+	v := new(int)
+	b.Run("Queue", func(b *testing.B) {
+		q := lockfree.NewQueue()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				q.Enqueue(v)
+			}
+		})
+	})
+	b.Run("Producer", func(b *testing.B) {
+		q := lockfree.NewQueue()
+		b.RunParallel(func(pb *testing.PB) {
+			p := q.NewProducer(64, 100*time.Microsecond)
+			defer p.Close()
+			for pb.Next() {
+				p.Enqueue(v)
+			}
+		})
+	})
+}
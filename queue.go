@@ -9,32 +9,48 @@ import (
 type Queue struct {
 	head unsafe.Pointer
 	tail unsafe.Pointer
-	len  uint64
-	pool sync.Pool
+	// enqueued 与 dequeued 是累计的入队与出队元素数，两者之差即队列长度。
+	// 生产者与消费者各自只更新其中一个计数器。
+	enqueued uint64
+	dequeued uint64
+	closed   int32
+	waits    waitList
+	leaks    leakState
+	pool     sync.Pool
+	reclaim  Reclamation
+	// tailHelp 是 WithTailHelpRate 设置的帮助频率，为 0 时使用 SetTailHelpRate 的默认值。
+	tailHelp int32
+	ep       epochState
 }
 
 // NewQueue 创建并返回一个新的队列实例。
 // 该函数通过初始化队列的头部和尾部指针，并设置队列长度为0，以及配置一个用于回收directItem的同步池。
+// opts 可以改变队列的行为，例如用 WithReclamation 选择节点的回收方式。
 // 返回值:
 //
 //	*Queue - 一个指向新创建的队列的指针。
-func NewQueue() *Queue {
+func NewQueue(opts ...Option) *Queue {
 	// 初始化队列的头部，它是一个特殊的directItem，其next指向第一个有效元素，v为nil表示头部不存储值。
 	head := directItem{
 		next: nil,
 		v:    nil,
 	}
 	// 返回新的队列实例
-	return &Queue{
+	q := &Queue{
 		head: unsafe.Pointer(&head), // 设置头部指针
 		tail: unsafe.Pointer(&head), // 设置尾部指针，初始时与头部相同
-		len:  0,                     // 初始队列长度为0
 		pool: sync.Pool{ // 初始化同步池，用于directItem的回收
 			New: func() any {
 				return &directItem{} // 池的New方法，用于生成新的directItem实例
 			},
 		},
 	}
+	for _, opt := range opts {
+		opt(q)
+	}
+	// 头部节点之后会像其他节点一样被回收到池中，因此同样视为已取出
+	trackGet(q, &head)
+	return q
 }
 
 // Enqueue 将一个元素添加到队列的末尾。
@@ -43,35 +59,64 @@ func NewQueue() *Queue {
 //
 //	v: 要添加到队列的元素，可以是任何类型的值。
 func (q *Queue) Enqueue(v any) {
-	// 从共享池中获取一个directItem，并初始化它。
+	// 从共享池中获取一个directItem（ReclaimGC 方式下新分配一个），并初始化它。
 	// 这样做既减少了内存分配的开销，也统一了队列元素的管理。
-	i := q.pool.Get().(*directItem)
+	q.enqueue(q.newItem(), v)
+}
+
+// enqueue 用节点 i 保存 v，并将其链接到队列末尾。
+func (q *Queue) enqueue(i *directItem, v any) {
 	i.next = nil
 	i.v = v
+	q.enqueueChain(i, i, 1)
+}
 
-	// 初始化last和lastNext指针，用于在循环中追踪队列的尾部。
-	var last, lastNext *directItem
+// enqueueChain 用一次CAS将一条已经链接好的、包含n个节点的链first...last追加到队列末尾。
+// last.next必须为nil。
+func (q *Queue) enqueueChain(first, last *directItem, n uint64) {
+	// 初始化tail、end和endNext指针，用于在循环中追踪队列的尾部。
+	var tail, end, endNext *directItem
+	var b backoff
+	// 读取尾部节点期间它可能被出队，pin 保证它在此期间不会被复用
+	e := q.pin()
+	defer q.unpin(e)
 
 	// 使用CAS操作循环尝试更新队列的尾部。
 	// 这个循环确保了在多线程环境下队列的尾部能够正确更新。
 	for {
 		// 加载当前队列的尾部指针。
-		last = loaditem(&q.tail)
+		tail = loaditem(&q.tail)
+		end = tail
 		// 加载当前尾部指针的下一个元素。
-		lastNext = loaditem(&last.next)
+		endNext = loaditem(&end.next)
 
 		// 再次检查队列的尾部指针是否未变，
 		// 这是必要的，因为在上一次加载后，可能已经被其他goroutine修改。
-		if loaditem(&q.tail) == last {
+		if loaditem(&q.tail) == end {
+			// 尾部指针落后且本次不帮助推进时，沿next指针找到真正的末尾，省去修正尾部的CAS。
+			// 沿途的节点可能已经出队，只有在节点不会被立即复用的回收方式下才能这样遍历。
+			if endNext != nil && q.reclaim != ReclaimPool && !q.helpTail() {
+				for endNext != nil {
+					end = endNext
+					endNext = loaditem(&end.next)
+				}
+				// 遍历期间尾部已被其他入队推进时，从新的尾部重新开始，而不是在落后的位置上竞争
+				if loaditem(&q.tail) != tail {
+					b.wait()
+					continue
+				}
+			}
 			// 如果当前尾部的下一个元素为空，说明可以将新元素添加到队列的末尾。
-			if lastNext == nil {
+			if endNext == nil {
 				// 使用CAS操作更新尾部的下一个元素为新元素，并更新队列的尾部指针。
 				// 这样做保证了更新操作的原子性，避免了竞态条件。
-				if casitem(&last.next, lastNext, i) {
+				if casitem(&end.next, endNext, first) {
 					// 更新队列的尾部指针，确保队列的尾部正确指向新的元素。
-					casitem(&q.tail, last, i)
-					// 原子性增加队列的长度。
-					atomic.AddUint64(&q.len, 1)
+					casitem(&q.tail, tail, last)
+					// 原子性增加入队计数，即队列的长度。
+					atomic.AddUint64(&q.enqueued, n)
+					// 唤醒阻塞在 DequeueWait 中的消费者，没有等待者时只是一次原子读
+					q.waits.wake(int(n))
 					// 添加成功，退出函数。
 					return
 				}
@@ -79,9 +124,11 @@ func (q *Queue) Enqueue(v any) {
 				// 如果当前尾部的下一个元素不为空，说明有其他goroutine已经添加了元素，
 				// 或者正在尝试添加。此时需要更新队列的尾部指针，以避免死锁。
 				// 这个操作确保了队列的持续可操作性，即使在高并发环境下。
-				casitem(&q.tail, last, lastNext)
+				casitem(&q.tail, end, endNext)
 			}
 		}
+		// 本轮 CAS 未成功，自旋或让出调度器后重试
+		b.wait()
 	}
 }
 
@@ -89,9 +136,41 @@ func (q *Queue) Enqueue(v any) {
 // 如果队列为空，函数返回 nil。
 // Dequeue 从队列中移除并返回一个元素。这个操作是线程安全的。
 // 如果队列为空，函数返回 nil。
+// 通过 EnqueueHandle 入队且已被 Handle.Remove 逻辑删除的元素会在这里被跳过并物理移除。
 func (q *Queue) Dequeue() interface{} {
+	v, _ := q.tryDequeue()
+	return v
+}
+
+// tryDequeue 与 Dequeue 相同，但用 ok 区分队列为空与取出了 nil 元素。
+func (q *Queue) tryDequeue() (interface{}, bool) {
+	e := q.pin()
+	defer q.unpin(e)
+	for {
+		v, freed := q.dequeue()
+		if freed == nil {
+			return nil, false
+		}
+		// 回收被移除的节点
+		q.free(freed)
+		if v, ok := unwrap(v); ok {
+			return v, true
+		}
+	}
+}
+
+// dequeue 从队列头部摘下一个节点，返回其中保存的原始值以及可以回收的旧头部节点，不处理 Handle。
+// 队列为空时返回的节点为 nil。调用方必须在 pin 状态下调用它并回收返回的节点。
+func (q *Queue) dequeue() (interface{}, *directItem) {
+	return q.dequeueIf(nil)
+}
+
+// dequeueIf 与 dequeue 相同，但只有当 accept 为 nil 或对队头的原始值返回 true 时才摘下节点，
+// 否则视同队列为空。CAS 失败重试时 accept 会对新的队头再次调用。
+func (q *Queue) dequeueIf(accept func(v any) bool) (interface{}, *directItem) {
 	// 定义指向队列首尾和首元素下一个元素的指针
 	var first, last, firstnext *directItem
+	var b backoff
 	for {
 		// 读取队列头部和尾部的元素
 		first = loaditem(&q.head)
@@ -105,28 +184,73 @@ func (q *Queue) Dequeue() interface{} {
 				// 如果队列确实为空
 				if firstnext == nil {
 					// 队列为空，无法移除元素，返回 nil
-					return nil
+					return nil, nil
 				}
 				// 尾部指针落后，尝试将其向前移动
 				casitem(&q.tail, last, firstnext)
 			} else {
 				// 在尝试交换头部指针之前读取值，否则另一个移除操作可能会释放下一个节点
 				v := firstnext.v
+				if accept != nil && !accept(v) {
+					return nil, nil
+				}
 				// 尝试将头部指针移动到下一个节点
 				if casitem(&q.head, first, firstnext) {
-					// 队列长度减一
-					atomic.AddUint64(&q.len, ^uint64(0))
-					// 回收被移除的元素
-					q.pool.Put(first)
-					// 返回移除的元素
-					return v
+					// 出队计数加一，即队列长度减一
+					atomic.AddUint64(&q.dequeued, 1)
+					// 返回移除的元素，旧的头部节点交给调用方回收
+					return v, first
 				}
 			}
 		}
+		// 本轮 CAS 未成功，自旋或让出调度器后重试
+		b.wait()
 	}
 }
 
 // Length returns the length of the queue.
 func (q *Queue) Length() uint64 {
-	return atomic.LoadUint64(&q.len)
+	return q.Stats().Depth
+}
+
+// Stats 是队列的累计计数。
+type Stats struct {
+	// Depth 是当前的队列长度。
+	Depth uint64
+	// Enqueued 与 Dequeued 是自队列创建以来入队与出队的元素总数。
+	Enqueued uint64
+	Dequeued uint64
+}
+
+// Stats 返回队列当前的累计计数。
+func (q *Queue) Stats() Stats {
+	// 先读出队计数：入队计数在链接节点之后才增加，读到的出队计数仍可能短暂超过入队计数
+	d := atomic.LoadUint64(&q.dequeued)
+	e := atomic.LoadUint64(&q.enqueued)
+	s := Stats{Enqueued: e, Dequeued: d}
+	if e > d {
+		s.Depth = e - d
+	}
+	return s
+}
+
+// Len 以 int 返回队列的长度，用于实现 Interface。
+func (q *Queue) Len() int {
+	return int(q.Length())
+}
+
+// Close 将队列标记为已关闭，重复调用返回 ErrClosed。
+// 关闭只是告知消费者不会再有新元素，队列中已有的元素仍然可以出队；
+// 阻塞在 DequeueWait 中的消费者会被全部唤醒，取完剩余元素后返回 ErrClosed。
+func (q *Queue) Close() error {
+	if !atomic.CompareAndSwapInt32(&q.closed, 0, 1) {
+		return ErrClosed
+	}
+	q.waits.wakeAll()
+	return nil
+}
+
+// Closed 报告队列是否已被关闭。
+func (q *Queue) Closed() bool {
+	return atomic.LoadInt32(&q.closed) != 0
 }
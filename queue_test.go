@@ -1,6 +1,7 @@
 package lockfreequeue_test
 
 import (
+	"errors"
 	"fmt"
 	"math/rand"
 	"sync"
@@ -109,3 +110,51 @@ func BenchmarkQueue(b *testing.B) {
 		})
 	}
 }
+
+func BenchmarkTailHelpRate(b *testing.B) {
+	for _, rate := range []int{1, 4, 16, 64} {
+		b.Run(fmt.Sprintf("rate=%d", rate), func(b *testing.B) {
+			q := lockfree.NewQueue(lockfree.WithTailHelpRate(rate))
+			v := new(int)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					q.Enqueue(v)
+				}
+			})
+		})
+		// 同时有出队时，遍历的节点可能正在被摘下，测量这种情况下的开销
+		b.Run(fmt.Sprintf("mixed/rate=%d", rate), func(b *testing.B) {
+			q := lockfree.NewQueue(lockfree.WithTailHelpRate(rate))
+			v := new(int)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					q.Enqueue(v)
+					q.Dequeue()
+				}
+			})
+		})
+	}
+}
+
+func TestInterface(t *testing.T) {
+	for name, q := range map[string]lockfree.Interface{
+		"Queue":     lockfree.NewQueue(),
+		"ChanQueue": lockfree.NewChanQueue(4),
+		"TopKTap":   lockfree.NewTopKTap(lockfree.NewQueue(), 1, func(any) string { return "" }, nil),
+	} {
+		q.Enqueue(1)
+		q.Enqueue(2)
+		if q.Len() != 2 {
+			t.Fatalf("%s: want length 2, got %d", name, q.Len())
+		}
+		if q.Dequeue() != 1 || q.Dequeue() != 2 || q.Dequeue() != nil {
+			t.Fatalf("%s: wrong order", name)
+		}
+		if err := q.Close(); err != nil {
+			t.Fatalf("%s: close: %v", name, err)
+		}
+		if err := q.Close(); !errors.Is(err, lockfree.ErrClosed) {
+			t.Fatalf("%s: second close should return ErrClosed, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package lockfreequeue
+
+import (
+	"strconv"
+	"sync/atomic"
+	"unsafe"
+)
+
+// Reclamation 决定队列如何回收已出队的节点。
+type Reclamation int
+
+const (
+	// ReclaimEpoch 在确认没有进行中的操作还可能引用节点之后才把它放回 sync.Pool 复用（基于 epoch 的延迟回收），
+	// 既避免了节点复用导致的 ABA 问题，又保留了池化带来的低分配。这是默认方式。
+	ReclaimEpoch Reclamation = iota
+	// ReclaimPool 在节点出队后立即把它放回 sync.Pool。这是最快的方式，
+	// 但节点可能在其他 goroutine 仍持有其指针时被复用，高竞争下存在 ABA 风险。
+	ReclaimPool
+	// ReclaimGC 从不复用节点，交给垃圾回收器回收。每次入队分配一个新节点，但不存在复用带来的任何风险。
+	ReclaimGC
+)
+
+// String 返回回收方式的名称。
+func (r Reclamation) String() string {
+	switch r {
+	case ReclaimEpoch:
+		return "epoch"
+	case ReclaimPool:
+		return "pool"
+	case ReclaimGC:
+		return "gc"
+	default:
+		return "Reclamation(" + strconv.Itoa(int(r)) + ")"
+	}
+}
+
+// Option 配置 NewQueue 创建的队列。
+type Option func(*Queue)
+
+// WithReclamation 设置队列回收已出队节点的方式，默认为 ReclaimEpoch。
+func WithReclamation(r Reclamation) Option {
+	return func(q *Queue) {
+		q.reclaim = r
+	}
+}
+
+// epochAdvanceEvery 是每回收多少个节点尝试推进一次 epoch。
+const epochAdvanceEvery = 64
+
+// epochState 是 ReclaimEpoch 方式下的回收状态。
+//
+// 每个读取节点的操作在开始前 pin 到当前的 epoch e，即把 active[e%3] 加一，结束后再减一。
+// epoch 只有在没有操作仍 pin 在 e-1 时才能从 e 推进到 e+1，因此 pin 在 e 的操作结束之前 epoch 至多为 e+1。
+// 节点在摘下后读到的 epoch 为 g，则只有 pin 在 g 或更早的操作可能仍持有它；
+// 当 epoch 到达 g+2 时这些操作都已结束，节点可以安全地复用。
+// 等待中的节点按 g%3 保存在 limbo 中的三个无锁栈里，通过 directItem.retired 链接。
+type epochState struct {
+	epoch   uint64
+	retired uint32
+	active  [3]struct {
+		n int64
+		_ [cacheLineSize - 8]byte
+	}
+	limbo [3]unsafe.Pointer
+}
+
+// newItem 返回一个可以用于入队的空节点。
+func (q *Queue) newItem() *directItem {
+	var i *directItem
+	if q.reclaim == ReclaimGC {
+		i = &directItem{}
+	} else {
+		i = q.pool.Get().(*directItem)
+	}
+	trackGet(q, i)
+	return i
+}
+
+// pin 在读取队列节点之前调用，返回值必须传给 unpin。ReclaimEpoch 以外的方式下不做任何事。
+func (q *Queue) pin() uint64 {
+	if q.reclaim != ReclaimEpoch {
+		return 0
+	}
+	ep := &q.ep
+	for {
+		e := atomic.LoadUint64(&ep.epoch)
+		atomic.AddInt64(&ep.active[e%3].n, 1)
+		// 读取 epoch 与登记之间 epoch 可能已经推进，此时登记在旧的槽位上不能阻止回收，重试
+		if atomic.LoadUint64(&ep.epoch) == e {
+			return e
+		}
+		atomic.AddInt64(&ep.active[e%3].n, -1)
+	}
+}
+
+// unpin 结束由 pin 开始的临界区。
+func (q *Queue) unpin(e uint64) {
+	if q.reclaim == ReclaimEpoch {
+		atomic.AddInt64(&q.ep.active[e%3].n, -1)
+	}
+}
+
+// free 回收一个已经从队列中摘下的旧头部节点，ReclaimEpoch 方式下调用方必须处于 pin 状态。
+// 节点的 next 和 v 可能仍被其他 goroutine 读取，不能修改。
+func (q *Queue) free(i *directItem) {
+	trackPut(q, i)
+	switch q.reclaim {
+	case ReclaimPool:
+		q.pool.Put(i)
+	case ReclaimEpoch:
+		q.retire(i)
+	}
+}
+
+// retire 把节点放入当前 epoch 的 limbo，并不时尝试推进 epoch。
+func (q *Queue) retire(i *directItem) {
+	ep := &q.ep
+	limbo := &ep.limbo[atomic.LoadUint64(&ep.epoch)%3]
+	for {
+		top := atomic.LoadPointer(limbo)
+		i.retired = top
+		if atomic.CompareAndSwapPointer(limbo, top, unsafe.Pointer(i)) {
+			break
+		}
+	}
+	if atomic.AddUint32(&ep.retired, 1)%epochAdvanceEvery == 0 {
+		q.tryAdvance()
+	}
+}
+
+// tryAdvance 在没有操作 pin 在上一个 epoch 时把 epoch 从 e 推进到 e+1，
+// 并把两个 epoch 之前摘下的节点放回池中。调用方必须处于 pin 状态：
+// 如果调用方 pin 在 e-1，推进的条件不成立；如果 pin 在 e，则在它结束之前 epoch 不会再次推进，
+// 即在交换出 limbo 之前不会有新的节点放入同一个槽位。
+func (q *Queue) tryAdvance() {
+	ep := &q.ep
+	e := atomic.LoadUint64(&ep.epoch)
+	if atomic.LoadInt64(&ep.active[(e+2)%3].n) != 0 || !atomic.CompareAndSwapUint64(&ep.epoch, e, e+1) {
+		return
+	}
+	for i := (*directItem)(atomic.SwapPointer(&ep.limbo[(e+2)%3], nil)); i != nil; {
+		next := (*directItem)(i.retired)
+		q.pool.Put(i)
+		i = next
+	}
+}
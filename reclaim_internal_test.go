@@ -0,0 +1,77 @@
+package lockfreequeue
+
+import "testing"
+
+func TestEpochDefersReuse(t *testing.T) {
+	q := NewQueue()
+	for i := 0; i < 10; i++ {
+		q.Enqueue(i)
+	}
+	// 模拟一个仍持有节点指针、尚未结束的操作
+	e := q.pin()
+	retired := make(map[*directItem]bool)
+	for n := loaditem(&q.head); n != loaditem(&q.tail); n = loaditem(&n.next) {
+		retired[n] = true
+	}
+	for q.Dequeue() != nil {
+	}
+	for i := 0; i < 10*epochAdvanceEvery; i++ {
+		if n := q.newItem(); retired[n] {
+			t.Fatalf("node reused while a reader was pinned at epoch %d", e)
+		} else {
+			q.enqueue(n, i)
+		}
+		q.Dequeue()
+	}
+	q.unpin(e)
+
+	// 读者结束后节点重新回到池中
+	for i := 0; i < 10*epochAdvanceEvery; i++ {
+		q.Enqueue(i)
+		q.Dequeue()
+	}
+	if q.ep.epoch < e+2 {
+		t.Fatalf("epoch stuck at %d after reader unpinned at %d", q.ep.epoch, e)
+	}
+}
+
+func TestLocalDefersReuse(t *testing.T) {
+	q := NewQueue()
+	l := q.NewLocal()
+	for i := 0; i < 10; i++ {
+		l.Enqueue(i)
+	}
+	e := q.pin()
+	retired := make(map[*directItem]bool)
+	for n := loaditem(&q.head); n != loaditem(&q.tail); n = loaditem(&n.next) {
+		retired[n] = true
+	}
+	for l.Dequeue() != nil {
+	}
+	for i := 0; i < 10*localCacheSize; i++ {
+		if n := l.get(); retired[n] {
+			t.Fatalf("Local reused a node while a reader was pinned at epoch %d", e)
+		} else {
+			q.enqueue(n, i)
+		}
+		l.Dequeue()
+	}
+	if len(l.pending) >= localCacheSize {
+		t.Fatalf("pending nodes must be bounded, have %d", len(l.pending))
+	}
+	q.unpin(e)
+
+	// 读者结束后，摘下的节点进入私有缓存被复用
+	for i := 0; i < 10*localCacheSize && len(l.free) == 0; i++ {
+		l.Enqueue(i)
+		l.Dequeue()
+		l.promote()
+	}
+	if len(l.free) == 0 {
+		t.Fatal("no node became reusable after the reader unpinned")
+	}
+	l.Flush()
+	if len(l.free) != 0 || len(l.pending) != 0 {
+		t.Fatalf("Flush left %d free and %d pending nodes", len(l.free), len(l.pending))
+	}
+}
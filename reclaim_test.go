@@ -0,0 +1,62 @@
+package lockfreequeue_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+var reclamations = []lockfree.Reclamation{lockfree.ReclaimEpoch, lockfree.ReclaimPool, lockfree.ReclaimGC}
+
+func TestReclamation(t *testing.T) {
+	const workers, per = 4, 5000
+	for _, r := range reclamations {
+		t.Run(r.String(), func(t *testing.T) {
+			if r == lockfree.ReclaimPool && raceEnabled {
+				t.Skip("ReclaimPool reuses nodes that concurrent readers may still hold")
+			}
+			q := lockfree.NewQueue(lockfree.WithReclamation(r))
+			var wg sync.WaitGroup
+			var sum, n int64
+			for w := 0; w < workers; w++ {
+				wg.Add(1)
+				go func(w int) {
+					defer wg.Done()
+					for i := 1; i <= per; i++ {
+						q.Enqueue(i)
+						if v, ok := q.Dequeue().(int); ok {
+							atomic.AddInt64(&sum, int64(v))
+							atomic.AddInt64(&n, 1)
+						}
+					}
+				}(w)
+			}
+			wg.Wait()
+			for v := q.Dequeue(); v != nil; v = q.Dequeue() {
+				sum += int64(v.(int))
+				n++
+			}
+			if want := int64(workers * per * (per + 1) / 2); n != workers*per || sum != want {
+				t.Fatalf("want %d items summing to %d, got %d summing to %d", workers*per, want, n, sum)
+			}
+		})
+	}
+}
+
+func BenchmarkReclamation(b *testing.B) {
+	for _, r := range reclamations {
+		b.Run(fmt.Sprint(r), func(b *testing.B) {
+			q := lockfree.NewQueue(lockfree.WithReclamation(r))
+			v := new(int)
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					q.Enqueue(v)
+					q.Dequeue()
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package lockfreequeue
+
+import "sync/atomic"
+
+// Ref 是一个引用计数的共享元素。同一个元素被投递给 N 个订阅者时，
+// 每个订阅者持有一个引用，最后一个引用被释放时才调用 release 回收元素（例如把缓冲区还给池）。
+type Ref[T any] struct {
+	v       T
+	refs    int32
+	release func(T)
+}
+
+// NewRef 创建一个引用计数为 1 的 Ref，调用方持有这唯一的引用。release 可以为 nil。
+func NewRef[T any](v T, release func(T)) *Ref[T] {
+	return &Ref[T]{v: v, refs: 1, release: release}
+}
+
+// Value 返回共享的元素。只能在持有引用期间访问。
+func (r *Ref[T]) Value() T {
+	return r.v
+}
+
+// Retain 增加 n 个引用。
+func (r *Ref[T]) Retain(n int) {
+	if atomic.AddInt32(&r.refs, int32(n)) <= int32(n) {
+		panic("lockfreequeue: Retain on released Ref")
+	}
+}
+
+// Release 释放一个引用，最后一个引用被释放时调用 release 回调。
+func (r *Ref[T]) Release() {
+	switch n := atomic.AddInt32(&r.refs, -1); {
+	case n == 0:
+		if r.release != nil {
+			r.release(r.v)
+		}
+	case n < 0:
+		panic("lockfreequeue: Ref released too many times")
+	}
+}
+
+// Broadcast 为 qs 中的每个队列增加一个引用，然后把 r 入队到每个队列。
+// 每个队列的消费者取出 *Ref[T] 后负责调用一次 Release；调用方仍持有自己原有的引用。
+func Broadcast[T any](r *Ref[T], qs ...*Queue) {
+	if len(qs) == 0 {
+		return
+	}
+	r.Retain(len(qs))
+	for _, q := range qs {
+		q.Enqueue(r)
+	}
+}
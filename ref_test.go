@@ -0,0 +1,48 @@
+package lockfreequeue_test
+
+import (
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestRefBroadcast(t *testing.T) {
+	var released int
+	r := lockfree.NewRef([]byte("frame"), func([]byte) { released++ })
+
+	subscribers := []*lockfree.Queue{lockfree.NewQueue(), lockfree.NewQueue(), lockfree.NewQueue()}
+	lockfree.Broadcast(r, subscribers...)
+	r.Release()
+	if released != 0 {
+		t.Fatalf("released while subscribers still hold references")
+	}
+
+	var wg sync.WaitGroup
+	for _, q := range subscribers {
+		wg.Add(1)
+		go func(q *lockfree.Queue) {
+			defer wg.Done()
+			ref := q.Dequeue().(*lockfree.Ref[[]byte])
+			if string(ref.Value()) != "frame" {
+				t.Errorf("unexpected value %q", ref.Value())
+			}
+			ref.Release()
+		}(q)
+	}
+	wg.Wait()
+	if released != 1 {
+		t.Fatalf("release called %d times, want 1", released)
+	}
+}
+
+func TestRefOverRelease(t *testing.T) {
+	r := lockfree.NewRef(1, nil)
+	r.Release()
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected panic on over-release")
+		}
+	}()
+	r.Release()
+}
@@ -0,0 +1,96 @@
+package lockfreequeue
+
+import (
+	"container/heap"
+	"sync"
+)
+
+type reorderHeap struct {
+	items []any
+	less  Less
+}
+
+func (h *reorderHeap) Len() int           { return len(h.items) }
+func (h *reorderHeap) Less(i, j int) bool { return h.less(h.items[i], h.items[j]) }
+func (h *reorderHeap) Swap(i, j int)      { h.items[i], h.items[j] = h.items[j], h.items[i] }
+func (h *reorderHeap) Push(x any)         { h.items = append(h.items, x) }
+func (h *reorderHeap) Pop() any {
+	n := len(h.items) - 1
+	v := h.items[n]
+	h.items[n] = nil
+	h.items = h.items[:n]
+	return v
+}
+
+// ReorderBuffer 是一个有界的重排缓冲区：多个上游分片可能略微乱序地提交元素，
+// 只要乱序程度不超过 window 个元素，下游队列看到的输出就是按 less 单调有序的。
+//
+// 缓冲区最多保留 window 个元素，超过时释放其中最小的一个；
+// 比已释放元素还小的迟到元素无法再保证有序，会被拒绝。
+type ReorderBuffer struct {
+	out    *Queue
+	window int
+
+	mu       sync.Mutex
+	h        reorderHeap
+	last     any
+	released bool
+	late     uint64
+}
+
+// NewReorderBuffer 创建一个容忍 window 个元素乱序、向 out 释放元素的 ReorderBuffer。
+func NewReorderBuffer(out *Queue, window int, less Less) *ReorderBuffer {
+	if window < 1 {
+		window = 1
+	}
+	return &ReorderBuffer{
+		out:    out,
+		window: window,
+		h:      reorderHeap{items: make([]any, 0, window+1), less: less},
+	}
+}
+
+// Push 提交一个元素。元素小于已释放的最后一个元素时被拒绝并返回 false。
+func (b *ReorderBuffer) Push(v any) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.released && b.h.less(v, b.last) {
+		b.late++
+		return false
+	}
+	heap.Push(&b.h, v)
+	if b.h.Len() > b.window {
+		b.releaseMin()
+	}
+	return true
+}
+
+// Flush 按顺序释放缓冲区中的全部元素，通常在上游全部结束时调用。
+func (b *ReorderBuffer) Flush() {
+	b.mu.Lock()
+	for b.h.Len() > 0 {
+		b.releaseMin()
+	}
+	b.mu.Unlock()
+}
+
+// Buffered 返回缓冲区中尚未释放的元素个数。
+func (b *ReorderBuffer) Buffered() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.h.Len()
+}
+
+// Late 返回因迟到而被拒绝的元素个数。
+func (b *ReorderBuffer) Late() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.late
+}
+
+// releaseMin 释放最小的元素，调用方必须持有 b.mu。
+func (b *ReorderBuffer) releaseMin() {
+	v := heap.Pop(&b.h)
+	b.last, b.released = v, true
+	b.out.Enqueue(v)
+}
@@ -0,0 +1,35 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestReorderBuffer(t *testing.T) {
+	out := lockfree.NewQueue()
+	b := lockfree.NewReorderBuffer(out, 3, func(a, b interface{}) bool { return a.(int) < b.(int) })
+
+	// 每个元素偏离正确位置不超过 3
+	for _, v := range []int{2, 1, 4, 3, 6, 5, 8, 7, 9} {
+		if !b.Push(v) {
+			t.Fatalf("item %d rejected", v)
+		}
+	}
+	if b.Buffered() != 3 {
+		t.Fatalf("want 3 buffered, got %d", b.Buffered())
+	}
+	if b.Push(0) {
+		t.Fatalf("item older than released output accepted")
+	}
+	b.Flush()
+
+	for want := 1; want <= 9; want++ {
+		if v := out.Dequeue(); v != want {
+			t.Fatalf("want %d, got %v", want, v)
+		}
+	}
+	if b.Late() != 1 {
+		t.Fatalf("late count wrong: %d", b.Late())
+	}
+}
@@ -0,0 +1,142 @@
+package lockfreequeue
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Resequencer 接收乱序到达、带序号的元素，并严格按序号顺序把它们放入下游队列。
+// 缺失的序号会阻塞后续元素的释放，直到它到达，或者缺口持续超过 GapTimeout：
+// 此时调用 OnGap 报告缺失的区间 [from, to]，并跳到已缓存的最小序号继续释放。
+type Resequencer struct {
+	// GapTimeout 是等待缺失序号的最长时间，为 0 表示一直等待。
+	GapTimeout time.Duration
+	// OnGap 在放弃等待缺失区间 [from, to] 时被调用，可以为 nil。
+	OnGap func(from, to uint64)
+
+	out      *Queue
+	mu       sync.Mutex
+	next     uint64
+	pending  map[uint64]any
+	gapSince time.Time
+}
+
+// NewResequencer 创建一个从序号 first 开始、向 out 释放元素的 Resequencer。
+func NewResequencer(out *Queue, first uint64, gapTimeout time.Duration) *Resequencer {
+	return &Resequencer{
+		GapTimeout: gapTimeout,
+		out:        out,
+		next:       first,
+		pending:    make(map[uint64]any),
+	}
+}
+
+// Push 提交序号为 seq 的元素。
+// 序号早于下一个待释放序号（迟到或重复）或已在缓存中的元素会被丢弃并返回 false。
+func (r *Resequencer) Push(seq uint64, v any) bool {
+	r.mu.Lock()
+	if seq < r.next {
+		r.mu.Unlock()
+		return false
+	}
+	if _, ok := r.pending[seq]; ok {
+		r.mu.Unlock()
+		return false
+	}
+	r.pending[seq] = v
+	r.release()
+	gap := r.checkGap(time.Now())
+	r.mu.Unlock()
+	r.reportGap(gap)
+	return true
+}
+
+// Check 检查当前缺口是否已超时，超时则跳过缺口。Run 会周期性地调用它。
+func (r *Resequencer) Check(now time.Time) {
+	r.mu.Lock()
+	gap := r.checkGap(now)
+	r.mu.Unlock()
+	r.reportGap(gap)
+}
+
+// Run 按 GapTimeout 的一半周期调用 Check，直到 ctx 结束。GapTimeout 为 0 时只等待 ctx 结束。
+func (r *Resequencer) Run(ctx context.Context) error {
+	if r.GapTimeout <= 0 {
+		<-ctx.Done()
+		return ctxErr(ctx)
+	}
+	t := time.NewTicker(r.GapTimeout / 2)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctxErr(ctx)
+		case now := <-t.C:
+			r.Check(now)
+		}
+	}
+}
+
+// Next 返回下一个待释放的序号。
+func (r *Resequencer) Next() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.next
+}
+
+// Pending 返回因等待缺失序号而缓存的元素个数。
+func (r *Resequencer) Pending() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.pending)
+}
+
+// release 释放从 next 开始连续的元素，调用方必须持有 r.mu。
+func (r *Resequencer) release() {
+	for {
+		v, ok := r.pending[r.next]
+		if !ok {
+			break
+		}
+		delete(r.pending, r.next)
+		r.out.Enqueue(v)
+		r.next++
+		r.gapSince = time.Time{}
+	}
+}
+
+// checkGap 在缺口超时时跳到最小的已缓存序号，返回被跳过的区间，调用方必须持有 r.mu。
+func (r *Resequencer) checkGap(now time.Time) *[2]uint64 {
+	if len(r.pending) == 0 || r.GapTimeout <= 0 {
+		return nil
+	}
+	if r.gapSince.IsZero() {
+		r.gapSince = now
+		return nil
+	}
+	if now.Sub(r.gapSince) < r.GapTimeout {
+		return nil
+	}
+	min := uint64(0)
+	first := true
+	for seq := range r.pending {
+		if first || seq < min {
+			min, first = seq, false
+		}
+	}
+	gap := &[2]uint64{r.next, min - 1}
+	r.next = min
+	r.gapSince = time.Time{}
+	r.release()
+	if len(r.pending) > 0 {
+		r.gapSince = now
+	}
+	return gap
+}
+
+func (r *Resequencer) reportGap(gap *[2]uint64) {
+	if gap != nil && r.OnGap != nil {
+		r.OnGap(gap[0], gap[1])
+	}
+}
@@ -0,0 +1,59 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestResequencer(t *testing.T) {
+	out := lockfree.NewQueue()
+	r := lockfree.NewResequencer(out, 1, 0)
+
+	r.Push(3, "c")
+	r.Push(2, "b")
+	if out.Length() != 0 || r.Pending() != 2 {
+		t.Fatalf("items released before the gap is filled")
+	}
+	r.Push(1, "a")
+	for _, want := range []string{"a", "b", "c"} {
+		if v := out.Dequeue(); v != want {
+			t.Fatalf("want %s, got %v", want, v)
+		}
+	}
+	if r.Push(2, "late") {
+		t.Fatalf("late item accepted")
+	}
+	if r.Next() != 4 {
+		t.Fatalf("next wrong: %d", r.Next())
+	}
+}
+
+func TestResequencerGapTimeout(t *testing.T) {
+	out := lockfree.NewQueue()
+	r := lockfree.NewResequencer(out, 1, 10*time.Millisecond)
+	var gaps [][2]uint64
+	r.OnGap = func(from, to uint64) { gaps = append(gaps, [2]uint64{from, to}) }
+
+	r.Push(1, "a")
+	r.Push(4, "d")
+	r.Push(5, "e")
+	r.Check(time.Now())
+	if out.Length() != 1 {
+		t.Fatalf("gap skipped before timeout")
+	}
+
+	r.Check(time.Now().Add(20 * time.Millisecond))
+	if len(gaps) != 1 || gaps[0] != [2]uint64{2, 3} {
+		t.Fatalf("unexpected gaps %v", gaps)
+	}
+	for _, want := range []string{"a", "d", "e"} {
+		if v := out.Dequeue(); v != want {
+			t.Fatalf("want %s, got %v", want, v)
+		}
+	}
+	if r.Next() != 6 {
+		t.Fatalf("next wrong: %d", r.Next())
+	}
+}
@@ -0,0 +1,75 @@
+package lockfreequeue
+
+import (
+	"math/rand"
+	"sync"
+)
+
+// ReservoirTap 包装一个队列，用蓄水池抽样（Algorithm R）保存入队元素的一个均匀随机样本，
+// 无需导出全部积压内容即可查看有代表性的元素。
+type ReservoirTap struct {
+	q *Queue
+
+	mu     sync.Mutex
+	seen   int64
+	sample []any
+}
+
+// NewReservoirTap 创建一个包装 q、样本容量为 size 的 ReservoirTap。
+func NewReservoirTap(q *Queue, size int) *ReservoirTap {
+	if size < 1 {
+		size = 1
+	}
+	return &ReservoirTap{
+		q:      q,
+		sample: make([]any, 0, size),
+	}
+}
+
+// Enqueue 以均匀概率将 v 纳入样本，并将其添加到队列末尾。
+func (t *ReservoirTap) Enqueue(v any) {
+	t.mu.Lock()
+	t.seen++
+	if len(t.sample) < cap(t.sample) {
+		t.sample = append(t.sample, v)
+	} else if j := rand.Int63n(t.seen); j < int64(len(t.sample)) {
+		t.sample[j] = v
+	}
+	t.mu.Unlock()
+	t.q.Enqueue(v)
+}
+
+// Dequeue 从队列中移除并返回一个元素。队列为空时返回 nil。
+func (t *ReservoirTap) Dequeue() any {
+	return t.q.Dequeue()
+}
+
+// Length returns the length of the underlying queue.
+func (t *ReservoirTap) Length() uint64 {
+	return t.q.Length()
+}
+
+// Len returns the length of the underlying queue as an int.
+func (t *ReservoirTap) Len() int {
+	return t.q.Len()
+}
+
+// Close 关闭底层队列。
+func (t *ReservoirTap) Close() error {
+	return t.q.Close()
+}
+
+// Sample 返回当前样本的副本以及迄今为止观察到的入队总数。
+func (t *ReservoirTap) Sample() ([]any, int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]any(nil), t.sample...), t.seen
+}
+
+// Reset 清空样本，之后的样本只反映 Reset 之后入队的元素。
+func (t *ReservoirTap) Reset() {
+	t.mu.Lock()
+	t.seen = 0
+	t.sample = t.sample[:0]
+	t.mu.Unlock()
+}
@@ -0,0 +1,37 @@
+package lockfreequeue_test
+
+import (
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestReservoirTap(t *testing.T) {
+	tap := lockfree.NewReservoirTap(lockfree.NewQueue(), 10)
+	for i := 0; i < 1000; i++ {
+		tap.Enqueue(i)
+	}
+
+	sample, seen := tap.Sample()
+	if len(sample) != 10 || seen != 1000 {
+		t.Fatalf("sample size %d, seen %d", len(sample), seen)
+	}
+	// 1000 个元素中抽取 10 个，全部来自前 10 个的概率可以忽略
+	late := 0
+	for _, v := range sample {
+		if v.(int) >= 10 {
+			late++
+		}
+	}
+	if late == 0 {
+		t.Fatalf("sample is not updated after the reservoir fills: %v", sample)
+	}
+	if tap.Length() != 1000 {
+		t.Fatalf("tap must not consume items, length %d", tap.Length())
+	}
+
+	tap.Reset()
+	if sample, seen := tap.Sample(); len(sample) != 0 || seen != 0 {
+		t.Fatalf("reset did not clear the sample")
+	}
+}
@@ -0,0 +1,93 @@
+package lockfreequeue
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DepthSample 是 Sampler 记录的一次采样。
+type DepthSample struct {
+	At    time.Time `json:"at"`
+	Depth uint64    `json:"depth"`
+	// EnqueueRate 与 DequeueRate 是自上一次采样以来的入队与出队速率（元素/秒），第一次采样时为 0。
+	EnqueueRate float64 `json:"enqueue_rate"`
+	DequeueRate float64 `json:"dequeue_rate"`
+}
+
+// Sampler 按 Interval 周期性记录队列长度与吞吐量，保存在容量固定的环形缓冲中，
+// 运维人员无需外部监控即可查看最近一段时间的积压趋势。
+// Sampler 实现了 http.Handler，可以直接挂到调试端口上，以 JSON 返回全部样本。
+type Sampler struct {
+	// Interval 是采样间隔，为 0 时使用 1 秒。
+	Interval time.Duration
+
+	q *Queue
+
+	mu      sync.Mutex
+	samples []DepthSample
+	next    int
+	last    Stats
+	lastAt  time.Time
+}
+
+// NewSampler 创建一个为 q 保留最近 size 个样本的 Sampler，size 小于 1 时视为 1。
+// 例如 Interval 为 1 秒、size 为 600 时保留最近 10 分钟的数据。
+func NewSampler(q *Queue, size int) *Sampler {
+	return &Sampler{q: q, samples: make([]DepthSample, 0, max(size, 1))}
+}
+
+// Run 按 Interval 持续采样，直到 ctx 结束。
+func (s *Sampler) Run(ctx context.Context) error {
+	interval := s.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctxErr(ctx)
+		case now := <-t.C:
+			s.Observe(now)
+		}
+	}
+}
+
+// Observe 在时刻 now 采样一次。Run 会自动调用它，也可以由调用方自行驱动。
+func (s *Sampler) Observe(now time.Time) {
+	st := s.q.Stats()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sample := DepthSample{At: now, Depth: st.Depth}
+	if elapsed := now.Sub(s.lastAt).Seconds(); !s.lastAt.IsZero() && elapsed > 0 {
+		sample.EnqueueRate = float64(st.Enqueued-s.last.Enqueued) / elapsed
+		sample.DequeueRate = float64(st.Dequeued-s.last.Dequeued) / elapsed
+	}
+	s.last, s.lastAt = st, now
+
+	if len(s.samples) < cap(s.samples) {
+		s.samples = append(s.samples, sample)
+		return
+	}
+	s.samples[s.next] = sample
+	s.next = (s.next + 1) % len(s.samples)
+}
+
+// Samples 按时间从早到晚返回保存的样本。
+func (s *Sampler) Samples() []DepthSample {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]DepthSample, 0, len(s.samples))
+	out = append(out, s.samples[s.next:]...)
+	return append(out, s.samples[:s.next]...)
+}
+
+// ServeHTTP 以 JSON 数组返回 Samples 的结果。
+func (s *Sampler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.Samples())
+}
@@ -0,0 +1,50 @@
+package lockfreequeue_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestSampler(t *testing.T) {
+	q := lockfree.NewQueue()
+	s := lockfree.NewSampler(q, 3)
+
+	now := time.Unix(0, 0)
+	for i := 1; i <= 4; i++ {
+		for j := 0; j < 10; j++ {
+			q.Enqueue(j)
+		}
+		for j := 0; j < 5; j++ {
+			q.Dequeue()
+		}
+		s.Observe(now)
+		now = now.Add(time.Second)
+	}
+
+	samples := s.Samples()
+	if len(samples) != 3 {
+		t.Fatalf("ring must keep the last 3 samples, got %d", len(samples))
+	}
+	for i, sm := range samples {
+		if want := uint64(5 * (i + 2)); sm.Depth != want {
+			t.Fatalf("sample %d: want depth %d, got %d", i, want, sm.Depth)
+		}
+		if sm.EnqueueRate != 10 || sm.DequeueRate != 5 {
+			t.Fatalf("sample %d: unexpected rates %+v", i, sm)
+		}
+	}
+	if st := q.Stats(); st.Enqueued != 40 || st.Dequeued != 20 || st.Depth != 20 {
+		t.Fatalf("unexpected stats %+v", st)
+	}
+
+	rec := httptest.NewRecorder()
+	s.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/queue", nil))
+	var got []lockfree.DepthSample
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || len(got) != 3 || got[2].Depth != 20 {
+		t.Fatalf("unexpected handler output %s (%v)", rec.Body.String(), err)
+	}
+}
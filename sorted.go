@@ -0,0 +1,83 @@
+package lockfreequeue
+
+import (
+	"cmp"
+	"sort"
+	"sync"
+)
+
+// SortedQueue 是一个并发的插入排序缓冲区：Enqueue 按顺序插入元素，Dequeue 弹出最小的元素，
+// 相等的元素按入队顺序出队。
+//
+// 内部是互斥锁保护的有序切片（按降序存放，最小元素位于末尾），入队为 O(n)，出队为 O(1)，
+// 适合元素不多、只需要“按序取出”的场景；不需要完整的优先队列时可以用它代替。
+type SortedQueue[T any] struct {
+	less func(a, b T) bool
+
+	mu    sync.Mutex
+	items []T
+}
+
+// NewSortedQueue 创建一个按 less 排序的 SortedQueue，less(a, b) 为 true 表示 a 排在 b 之前。
+func NewSortedQueue[T any](less func(a, b T) bool) *SortedQueue[T] {
+	return &SortedQueue[T]{less: less}
+}
+
+// NewSortedQueueOrdered 创建一个按 T 的自然顺序（<）排序的 SortedQueue。
+func NewSortedQueueOrdered[T cmp.Ordered]() *SortedQueue[T] {
+	return NewSortedQueue(cmp.Less[T])
+}
+
+// Enqueue 按顺序插入 v。
+func (q *SortedQueue[T]) Enqueue(v T) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// 插入到所有不大于 v 的元素之前，使相等的元素中先入队的更靠近末尾、先被弹出
+	i := sort.Search(len(q.items), func(i int) bool { return !q.less(v, q.items[i]) })
+	var zero T
+	q.items = append(q.items, zero)
+	copy(q.items[i+1:], q.items[i:])
+	q.items[i] = v
+}
+
+// Dequeue 弹出最小的元素。队列为空时 ok 为 false。
+func (q *SortedQueue[T]) Dequeue() (v T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	n := len(q.items)
+	if n == 0 {
+		return v, false
+	}
+	v = q.items[n-1]
+	var zero T
+	q.items[n-1] = zero
+	q.items = q.items[:n-1]
+	return v, true
+}
+
+// Peek 返回最小的元素但不移除它。队列为空时 ok 为 false。
+func (q *SortedQueue[T]) Peek() (v T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if n := len(q.items); n > 0 {
+		return q.items[n-1], true
+	}
+	return v, false
+}
+
+// PeekMax 返回最大的元素但不移除它，即最后才会被弹出的元素。队列为空时 ok 为 false。
+func (q *SortedQueue[T]) PeekMax() (v T, ok bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) > 0 {
+		return q.items[0], true
+	}
+	return v, false
+}
+
+// Len 返回队列中的元素个数。
+func (q *SortedQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items)
+}
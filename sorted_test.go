@@ -0,0 +1,64 @@
+package lockfreequeue_test
+
+import (
+	"math/rand"
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestSortedQueue(t *testing.T) {
+	q := lockfree.NewSortedQueueOrdered[int]()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 250; i++ {
+				q.Enqueue(rand.Intn(1000))
+			}
+		}()
+	}
+	wg.Wait()
+	if q.Len() != 1000 {
+		t.Fatalf("want 1000 items, got %d", q.Len())
+	}
+	if v, ok := q.Peek(); !ok || q.Len() != 1000 {
+		t.Fatalf("peek must not remove, got %v", v)
+	}
+	maxV, ok := q.PeekMax()
+	if !ok || q.Len() != 1000 {
+		t.Fatalf("peek max must not remove, got %v", maxV)
+	}
+	prev := -1
+	for i := 0; i < 1000; i++ {
+		v, ok := q.Dequeue()
+		if !ok || v < prev {
+			t.Fatalf("out of order at %d: %d after %d", i, v, prev)
+		}
+		prev = v
+	}
+	if prev != maxV {
+		t.Fatalf("peek max returned %d, last dequeued %d", maxV, prev)
+	}
+	if _, ok := q.Dequeue(); ok {
+		t.Fatalf("dequeue on empty queue returned ok")
+	}
+	if _, ok := q.PeekMax(); ok {
+		t.Fatalf("peek max on empty queue returned ok")
+	}
+}
+
+func TestSortedQueueStable(t *testing.T) {
+	type job struct{ prio, seq int }
+	q := lockfree.NewSortedQueue(func(a, b job) bool { return a.prio < b.prio })
+	for seq, prio := range []int{2, 1, 2, 1, 0} {
+		q.Enqueue(job{prio: prio, seq: seq})
+	}
+	for _, want := range []int{4, 1, 3, 0, 2} {
+		if j, _ := q.Dequeue(); j.seq != want {
+			t.Fatalf("want seq %d, got %+v", want, j)
+		}
+	}
+}
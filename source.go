@@ -0,0 +1,83 @@
+package lockfreequeue
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"time"
+)
+
+// pollInterval 是轮询等待（背压或空队列）时重新检查队列的间隔。
+const pollInterval = 100 * time.Microsecond
+
+// EnqueueJSON 从 r 中持续解码 JSON 值（换行分隔或直接拼接的对象流）为 T，并依次入队。
+// 当 limit 大于 0 且队列长度达到 limit 时，生产者会等待消费者把长度降下来再继续，从而形成背压。
+// 返回值:
+//
+//	int   - 成功入队的元素个数。
+//	error - 读到 io.EOF 时返回 nil，否则返回解码错误，或 ctx 结束时的 ErrCancelled、ErrTimeout。
+func EnqueueJSON[T any](ctx context.Context, q *Queue, r io.Reader, limit uint64) (int, error) {
+	dec := json.NewDecoder(r)
+	n := 0
+	for {
+		var v T
+		if err := dec.Decode(&v); err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+		if err := waitBelow(ctx, q, limit); err != nil {
+			return n, err
+		}
+		q.Enqueue(v)
+		n++
+	}
+}
+
+// EnqueueCSV 从 r 中逐行读取 CSV 记录，经 parse 转换为 T 后依次入队。
+// 背压语义与 EnqueueJSON 相同；parse 返回错误时立即停止并返回该错误。
+func EnqueueCSV[T any](ctx context.Context, q *Queue, r *csv.Reader, limit uint64, parse func(record []string) (T, error)) (int, error) {
+	n := 0
+	for {
+		record, err := r.Read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return n, nil
+			}
+			return n, err
+		}
+		v, err := parse(record)
+		if err != nil {
+			return n, err
+		}
+		if err := waitBelow(ctx, q, limit); err != nil {
+			return n, err
+		}
+		q.Enqueue(v)
+		n++
+	}
+}
+
+// waitBelow 阻塞直到队列长度小于 limit 或 ctx 结束。limit 为 0 表示不限制。
+func waitBelow(ctx context.Context, q *Queue, limit uint64) error {
+	if err := ctxErr(ctx); err != nil {
+		return err
+	}
+	if limit == 0 || q.Length() < limit {
+		return nil
+	}
+	t := time.NewTimer(pollInterval)
+	defer t.Stop()
+	for q.Length() >= limit {
+		select {
+		case <-ctx.Done():
+			return ctxErr(ctx)
+		case <-t.C:
+			t.Reset(pollInterval)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package lockfreequeue_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+type event struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestEnqueueJSON(t *testing.T) {
+	q := lockfree.NewQueue()
+	in := `{"id":1,"name":"a"}
+{"id":2,"name":"b"}
+{"id":3,"name":"c"}`
+	n, err := lockfree.EnqueueJSON[event](context.Background(), q, strings.NewReader(in), 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 3 || q.Length() != 3 {
+		t.Fatalf("enqueued count wrong, want %d, got %d (length %d)", 3, n, q.Length())
+	}
+	for i := 1; i <= 3; i++ {
+		e := q.Dequeue().(event)
+		if e.ID != i {
+			t.Fatalf("order wrong, want %d, got %d", i, e.ID)
+		}
+	}
+}
+
+func TestEnqueueJSONBackpressure(t *testing.T) {
+	q := lockfree.NewQueue()
+	in := strings.Repeat(`{"id":1}`, 10)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := lockfree.EnqueueJSON[event](context.Background(), q, strings.NewReader(in), 2); err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+	}()
+
+	got := 0
+	for got < 10 {
+		if q.Length() > 2 {
+			t.Fatalf("length exceeds limit: %d", q.Length())
+		}
+		if q.Dequeue() != nil {
+			got++
+		}
+	}
+	<-done
+}
+
+func TestEnqueueJSONCancel(t *testing.T) {
+	q := lockfree.NewQueue()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	n, err := lockfree.EnqueueJSON[event](ctx, q, strings.NewReader(`{"id":1}{"id":2}`), 1)
+	if !errors.Is(err, lockfree.ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected timeout error, got %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("enqueued count wrong, want %d, got %d", 1, n)
+	}
+}
+
+func TestEnqueueCSV(t *testing.T) {
+	q := lockfree.NewQueue()
+	r := csv.NewReader(strings.NewReader("1,a\n2,b\n"))
+	n, err := lockfree.EnqueueCSV(context.Background(), q, r, 0, func(record []string) (event, error) {
+		id, err := strconv.Atoi(record[0])
+		return event{ID: id, Name: record[1]}, err
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != 2 {
+		t.Fatalf("enqueued count wrong, want %d, got %d", 2, n)
+	}
+	if e := q.Dequeue().(event); e.ID != 1 || e.Name != "a" {
+		t.Fatalf("unexpected first record: %+v", e)
+	}
+}
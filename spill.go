@@ -0,0 +1,162 @@
+package lockfreequeue
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+)
+
+// Encoder 将队列元素逐个写入底层流。
+type Encoder interface {
+	Encode(v any) error
+}
+
+// Decoder 从底层流中逐个读出队列元素，流结束时返回 io.EOF。
+type Decoder interface {
+	Decode() (any, error)
+}
+
+// Codec 定义队列内容持久化时使用的编解码方式。
+type Codec interface {
+	NewEncoder(w io.Writer) Encoder
+	NewDecoder(r io.Reader) Decoder
+}
+
+// GobCodec 基于 encoding/gob 的 Codec。
+// 元素以接口值的形式编码，因此其具体类型必须事先通过 gob.Register 注册。
+type GobCodec struct{}
+
+// NewEncoder 实现 Codec。
+func (GobCodec) NewEncoder(w io.Writer) Encoder {
+	return gobEncoder{gob.NewEncoder(w)}
+}
+
+// NewDecoder 实现 Codec。
+func (GobCodec) NewDecoder(r io.Reader) Decoder {
+	return gobDecoder{gob.NewDecoder(r)}
+}
+
+type gobEncoder struct{ enc *gob.Encoder }
+
+func (e gobEncoder) Encode(v any) error {
+	return e.enc.Encode(&v)
+}
+
+type gobDecoder struct{ dec *gob.Decoder }
+
+func (d gobDecoder) Decode() (any, error) {
+	var v any
+	err := d.dec.Decode(&v)
+	return v, err
+}
+
+// Spill 取出队列中剩余的全部元素并写入 path。
+// 先写入同目录下的临时文件再重命名，保证 path 要么是完整的快照，要么不存在。
+// 任何一步失败时，队列中的全部元素会按原顺序放回，不会丢失；失败期间并发入队的元素可能与之交错。
+// 返回值:
+//
+//	int   - 写入的元素个数，失败时为 0。
+//	error - 创建文件、编码或重命名的错误。
+func Spill(q *Queue, path string, c Codec) (int, error) {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(f.Name())
+
+	var drained []any
+	err = spill(q, f, c, &drained)
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		// 连同尚未取出的元素一起放回，保持原有顺序
+		for v, ok := q.tryDequeue(); ok; v, ok = q.tryDequeue() {
+			drained = append(drained, v)
+		}
+		for _, v := range drained {
+			q.Enqueue(v)
+		}
+		return 0, err
+	}
+	return len(drained), nil
+}
+
+// spill 把队列内容编码写入 f 并关闭 f，取出的元素记录在 drained 中以便失败时放回。
+func spill(q *Queue, f *os.File, c Codec, drained *[]any) error {
+	enc := c.NewEncoder(f)
+	// 用 tryDequeue 而不是 Dequeue 判断队列是否为空，入队的 nil 元素同样需要写出
+	for v, ok := q.tryDequeue(); ok; v, ok = q.tryDequeue() {
+		*drained = append(*drained, v)
+		if err := enc.Encode(v); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Restore 将 Spill 写入 path 的元素重新入队，成功后删除该文件。
+// path 不存在时视为没有需要恢复的内容，返回 0, nil。
+// 文件会先被完整解码，中途出现解码错误时不入队任何元素并保留文件，
+// 因此修复后重试不会产生重复元素。
+func Restore(q *Queue, path string, c Codec) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	dec := c.NewDecoder(f)
+	var items []any
+	for {
+		v, err := dec.Decode()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return 0, err
+		}
+		items = append(items, v)
+	}
+	for _, v := range items {
+		q.Enqueue(v)
+	}
+	return len(items), os.Remove(path)
+}
+
+// SpillOnSignal 在收到 sigs 中的任一信号（默认 SIGTERM 与 os.Interrupt）时，将队列剩余内容 Spill 到 path。
+// 返回的 channel 在 Spill 完成后收到其错误并关闭；ctx 先结束时不做任何事，直接关闭 channel。
+// 调用方应在收到结果后退出进程，下次启动时通过 Restore 重新加载。
+func SpillOnSignal(ctx context.Context, q *Queue, path string, c Codec, sigs ...os.Signal) <-chan error {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGTERM, os.Interrupt}
+	}
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+
+	done := make(chan error, 1)
+	go func() {
+		defer close(done)
+		defer signal.Stop(ch)
+		select {
+		case <-ctx.Done():
+		case <-ch:
+			_, err := Spill(q, path, c)
+			done <- err
+		}
+	}()
+	return done
+}
@@ -0,0 +1,114 @@
+package lockfreequeue_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestSpillRestore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.spill")
+	q := lockfree.NewQueue()
+	q.Enqueue("a")
+	q.Enqueue(2)
+
+	n, err := lockfree.Spill(q, path, lockfree.GobCodec{})
+	if err != nil || n != 2 {
+		t.Fatalf("spill wrong, n=%d err=%v", n, err)
+	}
+	if q.Length() != 0 {
+		t.Fatalf("spill must drain the queue, length %d", q.Length())
+	}
+
+	r := lockfree.NewQueue()
+	n, err = lockfree.Restore(r, path, lockfree.GobCodec{})
+	if err != nil || n != 2 {
+		t.Fatalf("restore wrong, n=%d err=%v", n, err)
+	}
+	if r.Dequeue() != "a" || r.Dequeue() != 2 {
+		t.Fatalf("restored contents out of order")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("restore must remove the spill file")
+	}
+
+	if n, err := lockfree.Restore(r, path, lockfree.GobCodec{}); n != 0 || err != nil {
+		t.Fatalf("restore of missing file should be a no-op, n=%d err=%v", n, err)
+	}
+}
+
+func TestSpillNil(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.spill")
+	q := lockfree.NewQueue()
+	q.Enqueue("a")
+	q.Enqueue(nil)
+	q.Enqueue("b")
+
+	n, err := lockfree.Spill(q, path, lockfree.GobCodec{})
+	if err != nil || n != 3 {
+		t.Fatalf("spill must not stop at a queued nil, n=%d err=%v", n, err)
+	}
+	if q.Length() != 0 {
+		t.Fatalf("spill must drain the queue, length %d", q.Length())
+	}
+
+	r := lockfree.NewQueue()
+	if n, err := lockfree.Restore(r, path, lockfree.GobCodec{}); err != nil || n != 3 {
+		t.Fatalf("restore wrong, n=%d err=%v", n, err)
+	}
+	if r.Dequeue() != "a" || r.Dequeue() != nil || r.Dequeue() != "b" || r.Length() != 0 {
+		t.Fatalf("restored contents wrong")
+	}
+}
+
+type unregistered struct{ N int }
+
+func TestSpillEncodeError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.spill")
+	q := lockfree.NewQueue()
+	q.Enqueue("a")
+	q.Enqueue(unregistered{N: 1})
+	// 放回时同样不能在 nil 处停下
+	q.Enqueue(nil)
+	q.Enqueue("b")
+
+	if _, err := lockfree.Spill(q, path, lockfree.GobCodec{}); err == nil {
+		t.Fatalf("expected encode error for unregistered type")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("failed spill must not leave a snapshot")
+	}
+	if q.Dequeue() != "a" || q.Dequeue() != (unregistered{N: 1}) || q.Dequeue() != nil || q.Dequeue() != "b" || q.Length() != 0 {
+		t.Fatalf("failed spill must put every item back in order")
+	}
+}
+
+func TestRestoreDecodeError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.spill")
+	q := lockfree.NewQueue()
+	q.Enqueue("a")
+	q.Enqueue("b")
+	if _, err := lockfree.Spill(q, path, lockfree.GobCodec{}); err != nil {
+		t.Fatal(err)
+	}
+	// 截断文件末尾，使第二个元素解码失败
+	fi, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Truncate(path, fi.Size()-1); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := lockfree.Restore(q, path, lockfree.GobCodec{}); err == nil || n != 0 {
+		t.Fatalf("expected decode error and no items, n=%d err=%v", n, err)
+	}
+	if q.Length() != 0 {
+		t.Fatalf("failed restore must not enqueue a prefix, length %d", q.Length())
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("failed restore must keep the file: %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package lockfreequeue_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestSpillOnSignal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.spill")
+	q := lockfree.NewQueue()
+	q.Enqueue("pending")
+
+	done := lockfree.SpillOnSignal(context.Background(), q, path, lockfree.GobCodec{}, syscall.SIGUSR1)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	r := lockfree.NewQueue()
+	if n, _ := lockfree.Restore(r, path, lockfree.GobCodec{}); n != 1 {
+		t.Fatalf("restore wrong, n=%d", n)
+	}
+}
@@ -0,0 +1,78 @@
+package lockfreequeue
+
+import (
+	"math/rand/v2"
+	"runtime"
+	"sync/atomic"
+)
+
+// defaultSpinLimit 是自适应的默认自旋次数：单核机器上自旋只会浪费时间片，直接让出调度器；
+// 多核机器上先短暂自旋，等待持有竞争的 goroutine 在其他核上完成。
+var defaultSpinLimit = func() int32 {
+	if runtime.NumCPU() == 1 {
+		return 0
+	}
+	return 32
+}()
+
+var spinLimit = defaultSpinLimit
+
+// SetSpinLimit 设置 CAS 重试时在调用 runtime.Gosched 让出调度器之前紧密自旋的次数，返回之前的值。
+// n 为负数时恢复自适应的默认值（单核为 0，多核为 32）。
+// 核数少的边缘设备通常适合较小的值，核数多且竞争激烈的服务器适合较大的值。
+func SetSpinLimit(n int) int {
+	v := int32(n)
+	if n < 0 {
+		v = defaultSpinLimit
+	}
+	return int(atomic.SwapInt32(&spinLimit, v))
+}
+
+// backoff 记录一次操作中连续失败的次数，决定下一次重试前是自旋还是让出调度器。
+type backoff struct {
+	n int32
+}
+
+// wait 在重试前调用：未达到自旋上限时执行一次 CPU 自旋提示，否则让出调度器并重新计数。
+func (b *backoff) wait() {
+	if b.n < atomic.LoadInt32(&spinLimit) {
+		b.n++
+		cpuRelax()
+		return
+	}
+	b.n = 0
+	runtime.Gosched()
+}
+
+// tailHelpRate 是没有用 WithTailHelpRate 单独设置的队列使用的帮助频率，1 表示每次都帮助。
+var tailHelpRate int32 = 1
+
+// SetTailHelpRate 设置入队操作帮助推进滞后尾部指针的默认频率，返回之前的值。
+// n <= 1 时每次发现尾部落后都用 CAS 修正它（默认行为）；
+// n > 1 时平均每 n 次才修正一次，其余时候沿 next 指针向后找到真正的末尾直接链接，
+// 减少大量生产者并发时修正尾部带来的 CAS 流量，代价是尾部可能短暂地落后更多个节点。
+// 它影响进程内所有未通过 WithTailHelpRate 单独设置的队列；只调整某个热点队列时应使用 WithTailHelpRate。
+// 使用 ReclaimPool 的队列不受影响，总是帮助推进：沿途的节点可能已经出队并被复用。
+func SetTailHelpRate(n int) int {
+	if n < 1 {
+		n = 1
+	}
+	return int(atomic.SwapInt32(&tailHelpRate, int32(n)))
+}
+
+// WithTailHelpRate 为单个队列设置帮助推进滞后尾部指针的频率，含义与 SetTailHelpRate 相同，
+// 设置后该队列不再受 SetTailHelpRate 影响。n < 1 时按 1 处理。
+func WithTailHelpRate(n int) Option {
+	return func(q *Queue) {
+		q.tailHelp = int32(max(n, 1))
+	}
+}
+
+// helpTail 决定本次是否帮助推进滞后的尾部指针。
+func (q *Queue) helpTail() bool {
+	n := q.tailHelp
+	if n == 0 {
+		n = atomic.LoadInt32(&tailHelpRate)
+	}
+	return n <= 1 || rand.Uint32N(uint32(n)) == 0
+}
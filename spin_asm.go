@@ -0,0 +1,9 @@
+//go:build amd64 || arm64
+
+package lockfreequeue
+
+// cpuRelax 向 CPU 提示当前处于自旋等待（amd64 上为 PAUSE，arm64 上为 YIELD），
+// 降低对同一物理核上另一个超线程的干扰，并减少退出自旋时的流水线清空开销。
+//
+//go:noescape
+func cpuRelax()
@@ -0,0 +1,6 @@
+//go:build !amd64 && !arm64
+
+package lockfreequeue
+
+// cpuRelax 在没有自旋提示指令的平台上不做任何事。
+func cpuRelax() {}
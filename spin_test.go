@@ -0,0 +1,84 @@
+package lockfreequeue_test
+
+import (
+	"sync"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestSetSpinLimit(t *testing.T) {
+	prev := lockfree.SetSpinLimit(0)
+	defer lockfree.SetSpinLimit(prev)
+
+	// 自旋次数为 0 时每次重试都让出调度器，队列仍应正确工作
+	q := lockfree.NewQueue()
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				q.Enqueue(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if q.Length() != 4000 {
+		t.Fatalf("length wrong: %d", q.Length())
+	}
+
+	if got := lockfree.SetSpinLimit(-1); got != 0 {
+		t.Fatalf("previous limit wrong: %d", got)
+	}
+	if got := lockfree.SetSpinLimit(prev); got != prev {
+		t.Fatalf("negative limit should restore the default %d, got %d", prev, got)
+	}
+}
+
+func TestSetTailHelpRate(t *testing.T) {
+	prev := lockfree.SetTailHelpRate(16)
+	defer lockfree.SetTailHelpRate(prev)
+	checkTailHelp(t, lockfree.NewQueue())
+}
+
+func TestWithTailHelpRate(t *testing.T) {
+	// 单独设置的频率优先于进程级的默认值
+	prev := lockfree.SetTailHelpRate(1)
+	defer lockfree.SetTailHelpRate(prev)
+	checkTailHelp(t, lockfree.NewQueue(lockfree.WithTailHelpRate(16)))
+	checkTailHelp(t, lockfree.NewQueue(lockfree.WithTailHelpRate(0)))
+}
+
+func checkTailHelp(t *testing.T, q *lockfree.Queue) {
+	t.Helper()
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				q.Enqueue(g*1000 + i)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// 每个生产者的元素保持各自的先后顺序，且不丢失
+	last := make([]int, 8)
+	for i := range last {
+		last[i] = -1
+	}
+	n := 0
+	for v := q.Dequeue(); v != nil; v = q.Dequeue() {
+		g, i := v.(int)/1000, v.(int)%1000
+		if i <= last[g] {
+			t.Fatalf("producer %d out of order: %d after %d", g, i, last[g])
+		}
+		last[g] = i
+		n++
+	}
+	if n != 8000 {
+		t.Fatalf("want 8000 items, got %d", n)
+	}
+}
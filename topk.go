@@ -0,0 +1,147 @@
+package lockfreequeue
+
+import (
+	"sort"
+	"sync"
+)
+
+// TopKEntry 是 TopKTap 统计出的一个热点键。
+type TopKEntry struct {
+	Key string
+	// Weight 是该键当前在队列中的估计总权重，可能偏大，但偏差不超过 Error。
+	Weight int64
+	// Error 是估计的最大误差，来自该键进入统计时所替换掉的条目。
+	Error int64
+}
+
+// TopKTap 包装一个队列，按用户提供的键与权重函数近似统计当前排队元素中权重最大的 K 个键，
+// 例如按租户统计积压的载荷字节数，用于观察是什么占满了积压的队列。
+//
+// 统计采用 Space-Saving 算法，最多跟踪 4*K 个键，内存占用固定；
+// 出队时对应键的权重会被扣除，已被淘汰的键则忽略。
+type TopKTap struct {
+	q      *Queue
+	k      int
+	key    func(v any) string
+	weight func(v any) int64
+
+	mu      sync.Mutex
+	entries map[string]*topKEntry
+}
+
+// topKEntry 在 TopKEntry 之外记录该键进入统计之后实际观察到的权重。
+// 继承来的误差部分并不对应任何排队元素，当观察到的权重全部出队后条目即被删除。
+type topKEntry struct {
+	TopKEntry
+	observed int64
+}
+
+// NewTopKTap 创建一个包装 q 的 TopKTap。weight 为 nil 时每个元素的权重为 1。
+func NewTopKTap(q *Queue, k int, key func(v any) string, weight func(v any) int64) *TopKTap {
+	if k < 1 {
+		k = 1
+	}
+	if weight == nil {
+		weight = func(any) int64 { return 1 }
+	}
+	return &TopKTap{
+		q:       q,
+		k:       k,
+		key:     key,
+		weight:  weight,
+		entries: make(map[string]*topKEntry, 4*k),
+	}
+}
+
+// Enqueue 统计 v 的权重并将其添加到队列末尾。
+func (t *TopKTap) Enqueue(v any) {
+	t.add(t.key(v), t.weight(v))
+	t.q.Enqueue(v)
+}
+
+// Dequeue 从队列中移除并返回一个元素，同时扣除其权重。队列为空时返回 nil。
+func (t *TopKTap) Dequeue() any {
+	v := t.q.Dequeue()
+	if v != nil {
+		t.remove(t.key(v), t.weight(v))
+	}
+	return v
+}
+
+// Length returns the length of the underlying queue.
+func (t *TopKTap) Length() uint64 {
+	return t.q.Length()
+}
+
+// Len returns the length of the underlying queue as an int.
+func (t *TopKTap) Len() int {
+	return t.q.Len()
+}
+
+// Close 关闭底层队列。
+func (t *TopKTap) Close() error {
+	return t.q.Close()
+}
+
+// Top 返回按权重降序排列的至多 K 个热点键。
+func (t *TopKTap) Top() []TopKEntry {
+	t.mu.Lock()
+	top := make([]TopKEntry, 0, len(t.entries))
+	for _, e := range t.entries {
+		top = append(top, e.TopKEntry)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Weight != top[j].Weight {
+			return top[i].Weight > top[j].Weight
+		}
+		return top[i].Key < top[j].Key
+	})
+	if len(top) > t.k {
+		top = top[:t.k]
+	}
+	return top
+}
+
+func (t *TopKTap) add(key string, w int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if e, ok := t.entries[key]; ok {
+		e.Weight += w
+		e.observed += w
+		return
+	}
+	if len(t.entries) < 4*t.k {
+		t.entries[key] = &topKEntry{TopKEntry: TopKEntry{Key: key, Weight: w}, observed: w}
+		return
+	}
+	// 替换当前最小的条目，新键继承其权重作为误差上界
+	var min *topKEntry
+	for _, e := range t.entries {
+		if min == nil || e.Weight < min.Weight {
+			min = e
+		}
+	}
+	delete(t.entries, min.Key)
+	t.entries[key] = &topKEntry{
+		TopKEntry: TopKEntry{Key: key, Weight: min.Weight + w, Error: min.Weight},
+		observed:  w,
+	}
+}
+
+func (t *TopKTap) remove(key string, w int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.entries[key]
+	if !ok {
+		return
+	}
+	e.Weight -= w
+	e.observed -= w
+	if e.Weight <= 0 || e.observed <= 0 {
+		delete(t.entries, key)
+	} else if e.Error > e.Weight {
+		e.Error = e.Weight
+	}
+}
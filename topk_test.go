@@ -0,0 +1,41 @@
+package lockfreequeue_test
+
+import (
+	"fmt"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestTopKTap(t *testing.T) {
+	type job struct {
+		tenant string
+		size   int64
+	}
+	tap := lockfree.NewTopKTap(lockfree.NewQueue(), 2,
+		func(v any) string { return v.(job).tenant },
+		func(v any) int64 { return v.(job).size })
+
+	for i := 0; i < 100; i++ {
+		tap.Enqueue(job{tenant: "big", size: 10})
+		tap.Enqueue(job{tenant: fmt.Sprintf("small-%d", i), size: 1})
+	}
+	tap.Enqueue(job{tenant: "medium", size: 500})
+
+	top := tap.Top()
+	if len(top) != 2 {
+		t.Fatalf("want 2 entries, got %d", len(top))
+	}
+	if top[0].Key != "big" || top[0].Weight < 1000 {
+		t.Fatalf("unexpected top entry: %+v", top[0])
+	}
+	if top[1].Key != "medium" {
+		t.Fatalf("unexpected second entry: %+v", top[1])
+	}
+
+	for tap.Dequeue() != nil {
+	}
+	if top := tap.Top(); len(top) != 0 {
+		t.Fatalf("weights must be released on dequeue: %+v", top)
+	}
+}
@@ -0,0 +1,67 @@
+package lockfreequeue
+
+import (
+	"runtime"
+	"sort"
+)
+
+// CPU 描述一个逻辑 CPU 在拓扑中的位置。
+type CPU struct {
+	ID int
+	// Package 是物理插槽编号。
+	Package int
+	// Core 是插槽内的物理核编号，同一物理核上的超线程 Core 相同。
+	Core int
+	// LLC 标识共享末级缓存的 CPU 组，取该组中最小的 CPU 编号。
+	LLC int
+}
+
+// Placement 返回为 n 个消费者（或队列分片）推荐的 CPU 编号，用于配合 PinThread 绑定。
+// 分配时先填满一个末级缓存组，组内优先使用不同的物理核，物理核用完后再使用超线程兄弟，
+// 然后才进入下一个末级缓存组，从而尽量减少跨 LLC 的缓存行传输。
+// CPU 数量少于 n 时循环分配；拓扑信息不可用时按 0..NumCPU-1 的顺序分配。n 不大于 0 时返回 nil。
+func Placement(n int) []int {
+	if n <= 0 {
+		return nil
+	}
+	order := placementOrder(Topology())
+	if len(order) == 0 {
+		for i := 0; i < runtime.NumCPU(); i++ {
+			order = append(order, i)
+		}
+	}
+	cpus := make([]int, n)
+	for i := range cpus {
+		cpus[i] = order[i%len(order)]
+	}
+	return cpus
+}
+
+func placementOrder(topo []CPU) []int {
+	cpus := append([]CPU(nil), topo...)
+	// 同一物理核上的第 k 个超线程排在所有物理核的第 k-1 个超线程之后
+	rank := make(map[[3]int]int)
+	ranks := make([]int, len(cpus))
+	sort.Slice(cpus, func(i, j int) bool { return cpus[i].ID < cpus[j].ID })
+	for i, c := range cpus {
+		key := [3]int{c.LLC, c.Package, c.Core}
+		ranks[i] = rank[key]
+		rank[key]++
+	}
+	idx := make([]int, len(cpus))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		x, y := cpus[idx[a]], cpus[idx[b]]
+		if x.LLC != y.LLC {
+			return x.LLC < y.LLC
+		}
+		return ranks[idx[a]] < ranks[idx[b]]
+	})
+	order := make([]int, len(idx))
+	for i, k := range idx {
+		order[i] = cpus[k].ID
+	}
+	return order
+}
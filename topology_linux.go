@@ -0,0 +1,87 @@
+package lockfreequeue
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// cpuMask 是 sched_setaffinity/sched_getaffinity 使用的 CPU 位图，最多 1024 个 CPU。
+type cpuMask [16]uint64
+
+func (m *cpuMask) has(cpu int) bool {
+	return cpu >= 0 && cpu < len(m)*64 && m[cpu/64]&(1<<(uint(cpu)%64)) != 0
+}
+
+// Topology 从 /sys/devices/system/cpu 读取当前机器的 CPU 拓扑，读取失败时返回 nil。
+// 结果只包含当前进程的 CPU 亲和性掩码（如 taskset、cgroup cpuset）允许使用的 CPU。
+func Topology() []CPU {
+	dirs, err := filepath.Glob("/sys/devices/system/cpu/cpu[0-9]*")
+	if err != nil || len(dirs) == 0 {
+		return nil
+	}
+	var allowed cpuMask
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_GETAFFINITY, 0, unsafe.Sizeof(allowed), uintptr(unsafe.Pointer(&allowed[0])))
+	cpus := make([]CPU, 0, len(dirs))
+	for _, dir := range dirs {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "cpu"))
+		if err != nil || (errno == 0 && !allowed.has(id)) {
+			continue
+		}
+		pkg, ok1 := readSysInt(filepath.Join(dir, "topology", "physical_package_id"))
+		core, ok2 := readSysInt(filepath.Join(dir, "topology", "core_id"))
+		if !ok1 || !ok2 {
+			// 离线的 CPU 没有 topology 目录
+			continue
+		}
+		llc := pkg
+		if list, err := os.ReadFile(filepath.Join(dir, "cache", "index3", "shared_cpu_list")); err == nil {
+			if first, ok := firstCPU(string(list)); ok {
+				llc = first
+			}
+		}
+		cpus = append(cpus, CPU{ID: id, Package: pkg, Core: core, LLC: llc})
+	}
+	return cpus
+}
+
+// PinThread 将当前 goroutine 锁定到其 OS 线程，并把该线程绑定到 cpu 上运行。
+// 调用方应在消费者 goroutine 的开头调用它；goroutine 退出后线程随之销毁。
+// 绑定失败时 goroutine 不会被锁定到线程。
+func PinThread(cpu int) error {
+	var mask cpuMask
+	if cpu < 0 || cpu >= len(mask)*64 {
+		return syscall.EINVAL
+	}
+	mask[cpu/64] |= 1 << (uint(cpu) % 64)
+	runtime.LockOSThread()
+	_, _, errno := syscall.RawSyscall(syscall.SYS_SCHED_SETAFFINITY, 0, unsafe.Sizeof(mask), uintptr(unsafe.Pointer(&mask[0])))
+	if errno != 0 {
+		runtime.UnlockOSThread()
+		return errno
+	}
+	return nil
+}
+
+func readSysInt(path string) (int, bool) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	return n, err == nil
+}
+
+// firstCPU 返回形如 "0-3,8-11" 的 CPU 列表中的第一个编号。
+func firstCPU(list string) (int, bool) {
+	list = strings.TrimSpace(list)
+	if i := strings.IndexAny(list, ",-"); i >= 0 {
+		list = list[:i]
+	}
+	n, err := strconv.Atoi(list)
+	return n, err == nil
+}
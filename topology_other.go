@@ -0,0 +1,13 @@
+//go:build !linux
+
+package lockfreequeue
+
+// Topology 在非 Linux 平台上不可用，总是返回 nil。
+func Topology() []CPU {
+	return nil
+}
+
+// PinThread 在非 Linux 平台上不做任何事。
+func PinThread(cpu int) error {
+	return nil
+}
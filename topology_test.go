@@ -0,0 +1,42 @@
+package lockfreequeue_test
+
+import (
+	"runtime"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestPlacement(t *testing.T) {
+	n := 2 * runtime.NumCPU()
+	cpus := lockfree.Placement(n)
+	if len(cpus) != n {
+		t.Fatalf("want %d cpus, got %d", n, len(cpus))
+	}
+	seen := make(map[int]bool)
+	for _, c := range cpus[:runtime.NumCPU()] {
+		seen[c] = true
+	}
+	if topo := lockfree.Topology(); topo != nil && len(seen) != len(topo) {
+		t.Fatalf("first round should use every cpu once, got %v", cpus)
+	}
+	if cpus := lockfree.Placement(-1); cpus != nil {
+		t.Fatalf("negative count should yield no cpus, got %v", cpus)
+	}
+}
+
+func TestPinThread(t *testing.T) {
+	done := make(chan error)
+	go func() {
+		done <- lockfree.PinThread(lockfree.Placement(1)[0])
+	}()
+	if err := <-done; err != nil {
+		t.Fatalf("pin failed: %v", err)
+	}
+}
+
+func TestPinThreadInvalid(t *testing.T) {
+	if err := lockfree.PinThread(-1); err == nil && runtime.GOOS == "linux" {
+		t.Fatalf("expected error for invalid cpu")
+	}
+}
@@ -0,0 +1,87 @@
+package lockfreequeue
+
+import (
+	"context"
+	"runtime/trace"
+	"time"
+)
+
+type tracedItem struct {
+	v    any
+	at   time.Time
+	ctx  context.Context
+	task *trace.Task
+}
+
+// TraceQueue 包装一个队列，用 runtime/trace 的任务与区域标注入队、出队、
+// 元素在队列中的停留时间以及消费者处理过程，使 `go tool trace` 能把队列的交接与 goroutine 调度对应起来。
+//
+// 每个元素对应一个名为 "lockfreequeue.item" 的任务，从入队开始，到出队（或 Process 处理完成）结束，
+// 出队时在任务中记录停留时长。未开启 trace 时只保留入队时间，开销很小。
+type TraceQueue struct {
+	q *Queue
+}
+
+// NewTraceQueue 创建一个包装 q 的 TraceQueue。
+func NewTraceQueue(q *Queue) *TraceQueue {
+	return &TraceQueue{q: q}
+}
+
+// Enqueue 将 v 添加到队列末尾，元素任务是 ctx 中任务的子任务。
+func (t *TraceQueue) Enqueue(ctx context.Context, v any) {
+	item := &tracedItem{v: v, at: time.Now()}
+	if trace.IsEnabled() {
+		item.ctx, item.task = trace.NewTask(ctx, "lockfreequeue.item")
+		defer trace.StartRegion(item.ctx, "lockfreequeue.Enqueue").End()
+	}
+	t.q.Enqueue(item)
+}
+
+// Dequeue 从队列中移除并返回一个元素，并结束其任务。队列为空时返回 nil。
+func (t *TraceQueue) Dequeue(ctx context.Context) any {
+	item := t.dequeue(ctx)
+	if item == nil {
+		return nil
+	}
+	if item.task != nil {
+		item.task.End()
+	}
+	return item.v
+}
+
+// Process 取出一个元素，并在该元素任务下的 "lockfreequeue.handler" 区域中调用 fn，
+// fn 收到的 ctx 携带元素任务，可以继续创建子区域。队列为空时返回 false。
+func (t *TraceQueue) Process(ctx context.Context, fn func(ctx context.Context, v any)) bool {
+	item := t.dequeue(ctx)
+	if item == nil {
+		return false
+	}
+	if item.task == nil {
+		fn(ctx, item.v)
+		return true
+	}
+	defer item.task.End()
+	trace.WithRegion(item.ctx, "lockfreequeue.handler", func() {
+		fn(item.ctx, item.v)
+	})
+	return true
+}
+
+// Length returns the length of the underlying queue.
+func (t *TraceQueue) Length() uint64 {
+	return t.q.Length()
+}
+
+func (t *TraceQueue) dequeue(ctx context.Context) *tracedItem {
+	if !trace.IsEnabled() {
+		item, _ := t.q.Dequeue().(*tracedItem)
+		return item
+	}
+	region := trace.StartRegion(ctx, "lockfreequeue.Dequeue")
+	item, _ := t.q.Dequeue().(*tracedItem)
+	region.End()
+	if item != nil && item.task != nil {
+		trace.Log(item.ctx, "dwell", time.Since(item.at).String())
+	}
+	return item
+}
@@ -0,0 +1,34 @@
+package lockfreequeue_test
+
+import (
+	"bytes"
+	"context"
+	"runtime/trace"
+	"testing"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestTraceQueue(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skipf("trace unavailable: %v", err)
+	}
+	defer trace.Stop()
+
+	ctx := context.Background()
+	q := lockfree.NewTraceQueue(lockfree.NewQueue())
+	q.Enqueue(ctx, 1)
+	q.Enqueue(ctx, 2)
+
+	if v := q.Dequeue(ctx); v != 1 {
+		t.Fatalf("want 1, got %v", v)
+	}
+	var got any
+	if !q.Process(ctx, func(_ context.Context, v any) { got = v }) || got != 2 {
+		t.Fatalf("process wrong, got %v", got)
+	}
+	if q.Dequeue(ctx) != nil || q.Process(ctx, func(context.Context, any) {}) {
+		t.Fatalf("empty queue returns an item")
+	}
+}
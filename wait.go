@@ -0,0 +1,179 @@
+package lockfreequeue
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WakeOrder 决定多个消费者阻塞在 DequeueWait 中时，入队按什么顺序唤醒它们。
+type WakeOrder int
+
+const (
+	// WakeFIFO 按阻塞的先后顺序唤醒，等待延迟在消费者之间分布得更公平。这是默认顺序。
+	WakeFIFO WakeOrder = iota
+	// WakeLIFO 优先唤醒最近阻塞的消费者，它的栈与缓存更可能仍然是热的。
+	WakeLIFO
+)
+
+// WithWakeOrder 设置唤醒阻塞消费者的顺序，默认为 WakeFIFO。Close 总是唤醒全部消费者。
+func WithWakeOrder(o WakeOrder) Option {
+	return func(q *Queue) {
+		q.waits.order = o
+	}
+}
+
+// waitList 是阻塞在 DequeueWait 中的消费者按阻塞顺序排列的列表，每个等待者持有一个容量为 1 的唤醒 channel。
+// n 是等待者数量，入队路径只在它不为 0 时才加锁唤醒。
+type waitList struct {
+	n     int32
+	mu    sync.Mutex
+	w     []chan struct{}
+	order WakeOrder
+
+	// 阻塞与唤醒的累计统计，见 ParkStats
+	parks    uint64
+	wakes    uint64
+	spurious uint64
+	parked   int64
+}
+
+// add 登记一个新的等待者并返回其唤醒 channel。
+func (l *waitList) add() chan struct{} {
+	ch := make(chan struct{}, 1)
+	l.mu.Lock()
+	l.w = append(l.w, ch)
+	atomic.StoreInt32(&l.n, int32(len(l.w)))
+	l.mu.Unlock()
+	return ch
+}
+
+// remove 注销等待者 ch。ch 已经被唤醒而不在列表中时返回 false。
+func (l *waitList) remove(ch chan struct{}) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for i, w := range l.w {
+		if w == ch {
+			l.w = append(l.w[:i], l.w[i+1:]...)
+			atomic.StoreInt32(&l.n, int32(len(l.w)))
+			return true
+		}
+	}
+	return false
+}
+
+// wake 按 order 唤醒至多 n 个等待者。
+func (l *waitList) wake(n int) {
+	if atomic.LoadInt32(&l.n) == 0 {
+		return
+	}
+	l.mu.Lock()
+	n = min(n, len(l.w))
+	if l.order == WakeLIFO {
+		for _, ch := range l.w[len(l.w)-n:] {
+			ch <- struct{}{}
+		}
+		clear(l.w[len(l.w)-n:])
+		l.w = l.w[:len(l.w)-n]
+	} else {
+		for _, ch := range l.w[:n] {
+			ch <- struct{}{}
+		}
+		l.w = append(l.w[:0], l.w[n:]...)
+	}
+	atomic.StoreInt32(&l.n, int32(len(l.w)))
+	l.mu.Unlock()
+}
+
+// wakeAll 唤醒全部等待者。
+func (l *waitList) wakeAll() {
+	l.wake(int(^uint(0) >> 1))
+}
+
+// DequeueWait 从队列中移除并返回一个元素，队列为空时阻塞直到有元素入队、ctx 结束或队列被关闭。
+// 与 Dequeue 不同，入队的 nil 元素也会被正常返回。
+// 返回值:
+//
+//	any   - 移除的元素。
+//	error - ctx 结束时返回包装了 ErrTimeout 或 ErrCancelled 的错误；
+//	        队列已关闭且没有剩余元素时返回 ErrClosed。
+func (q *Queue) DequeueWait(ctx context.Context) (any, error) {
+	woken := false
+	for {
+		if v, ok := q.tryDequeue(); ok {
+			return v, nil
+		}
+		// 先登记再检查一次队列：入队在增加计数之后才读取等待者数量，
+		// 因此要么这里能取到元素，要么入队方能看到这个等待者
+		ch := q.waits.add()
+		if v, ok := q.tryDequeue(); ok {
+			q.cancelWait(ch)
+			return v, nil
+		}
+		if q.Closed() {
+			q.waits.remove(ch)
+			return nil, ErrClosed
+		}
+		if woken {
+			// 上一次唤醒对应的元素已被其他消费者取走
+			atomic.AddUint64(&q.waits.spurious, 1)
+		}
+		atomic.AddUint64(&q.waits.parks, 1)
+		start := time.Now()
+		select {
+		case <-ch:
+			atomic.AddUint64(&q.waits.wakes, 1)
+			atomic.AddInt64(&q.waits.parked, int64(time.Since(start)))
+			woken = true
+		case <-ctx.Done():
+			atomic.AddInt64(&q.waits.parked, int64(time.Since(start)))
+			q.cancelWait(ch)
+			return nil, ctxErr(ctx)
+		}
+	}
+}
+
+// ParkStats 是 DequeueWait 阻塞与唤醒的累计统计，用于确认阻塞出队在生产负载下没有频繁地空转。
+type ParkStats struct {
+	// Parks 是消费者因队列为空而阻塞的次数。
+	Parks uint64
+	// Wakes 是阻塞的消费者被入队或 Close 唤醒的次数，因 ctx 结束而返回的不计入。
+	Wakes uint64
+	// Spurious 是被唤醒后没有取到元素而再次阻塞的次数，即唤醒对应的元素被其他消费者抢先取走。
+	Spurious uint64
+	// Parked 是全部阻塞的累计时长。
+	Parked time.Duration
+}
+
+// AvgParked 返回平均每次阻塞的时长，没有阻塞过时返回 0。
+func (s ParkStats) AvgParked() time.Duration {
+	if s.Parks == 0 {
+		return 0
+	}
+	return s.Parked / time.Duration(s.Parks)
+}
+
+// ParkStats 返回队列上 DequeueWait 的阻塞统计。
+func (q *Queue) ParkStats() ParkStats {
+	return ParkStats{
+		Parks:    atomic.LoadUint64(&q.waits.parks),
+		Wakes:    atomic.LoadUint64(&q.waits.wakes),
+		Spurious: atomic.LoadUint64(&q.waits.spurious),
+		Parked:   time.Duration(atomic.LoadInt64(&q.waits.parked)),
+	}
+}
+
+// DequeueTimeout 与 DequeueWait 相同，但最多等待 d，超时返回包装了 ErrTimeout 的错误。
+func (q *Queue) DequeueTimeout(d time.Duration) (any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return q.DequeueWait(ctx)
+}
+
+// cancelWait 注销不再等待的 ch。如果 ch 已经被唤醒，这次唤醒对应的元素就交给下一个等待者。
+func (q *Queue) cancelWait(ch chan struct{}) {
+	if !q.waits.remove(ch) {
+		q.waits.wake(1)
+	}
+}
@@ -0,0 +1,150 @@
+package lockfreequeue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	lockfree "github.com/hawkli-1994/lockfreequeue"
+)
+
+func TestDequeueWait(t *testing.T) {
+	q := lockfree.NewQueue()
+	got := make(chan any)
+	go func() {
+		v, err := q.DequeueWait(context.Background())
+		if err != nil {
+			t.Error(err)
+		}
+		got <- v
+	}()
+	select {
+	case v := <-got:
+		t.Fatalf("returned %v before anything was enqueued", v)
+	case <-time.After(10 * time.Millisecond):
+	}
+	q.Enqueue(1)
+	if v := <-got; v != 1 {
+		t.Fatalf("want 1, got %v", v)
+	}
+
+	// 入队的 nil 元素也会被返回
+	q.Enqueue(nil)
+	if v, err := q.DequeueTimeout(time.Second); v != nil || err != nil {
+		t.Fatalf("want queued nil, got %v, %v", v, err)
+	}
+}
+
+func TestDequeueWaitTimeout(t *testing.T) {
+	q := lockfree.NewQueue()
+	if _, err := q.DequeueTimeout(time.Millisecond); !errors.Is(err, lockfree.ErrTimeout) {
+		t.Fatalf("want ErrTimeout, got %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(time.Millisecond)
+		cancel()
+	}()
+	if _, err := q.DequeueWait(ctx); !errors.Is(err, lockfree.ErrCancelled) {
+		t.Fatalf("want ErrCancelled, got %v", err)
+	}
+
+	// 放弃等待的消费者不会吞掉之后入队的元素
+	q.Enqueue(2)
+	if v, err := q.DequeueTimeout(time.Second); v != 2 || err != nil {
+		t.Fatalf("want 2, got %v, %v", v, err)
+	}
+}
+
+func TestDequeueWaitClose(t *testing.T) {
+	const workers = 4
+	q := lockfree.NewQueue()
+	var wg sync.WaitGroup
+	var n, closed int64
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				_, err := q.DequeueWait(context.Background())
+				if errors.Is(err, lockfree.ErrClosed) {
+					atomic.AddInt64(&closed, 1)
+					return
+				}
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				atomic.AddInt64(&n, 1)
+			}
+		}()
+	}
+	for i := 0; i < 1000; i++ {
+		q.Enqueue(i)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	if n != 1000 || closed != workers {
+		t.Fatalf("want 1000 items and %d closed workers, got %d and %d", workers, n, closed)
+	}
+	if _, err := q.DequeueWait(context.Background()); !errors.Is(err, lockfree.ErrClosed) {
+		t.Fatalf("want ErrClosed after close, got %v", err)
+	}
+}
+
+func TestParkStats(t *testing.T) {
+	q := lockfree.NewQueue()
+	if s := q.ParkStats(); s != (lockfree.ParkStats{}) || s.AvgParked() != 0 {
+		t.Fatalf("want zero stats, got %+v", s)
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if _, err := q.DequeueWait(context.Background()); err != nil {
+			t.Error(err)
+		}
+	}()
+	waitFor(t, func() bool { return q.ParkStats().Parks == 1 })
+	time.Sleep(time.Millisecond)
+	q.Enqueue(1)
+	<-done
+
+	q.DequeueTimeout(time.Millisecond)
+	s := q.ParkStats()
+	if s.Parks != 2 || s.Wakes != 1 || s.Spurious != 0 {
+		t.Fatalf("want 2 parks and 1 wake, got %+v", s)
+	}
+	if s.Parked < 2*time.Millisecond || s.AvgParked() != s.Parked/2 {
+		t.Fatalf("unexpected parked time %v (avg %v)", s.Parked, s.AvgParked())
+	}
+}
+
+func TestWakeOrder(t *testing.T) {
+	for _, tc := range []struct {
+		order lockfree.WakeOrder
+		want  int
+	}{{lockfree.WakeFIFO, 0}, {lockfree.WakeLIFO, 2}} {
+		q := lockfree.NewQueue(lockfree.WithWakeOrder(tc.order))
+		got := make(chan int, 3)
+		for w := 0; w < 3; w++ {
+			go func(w int) {
+				if _, err := q.DequeueWait(context.Background()); err == nil {
+					got <- w
+				}
+			}(w)
+			// 等这个消费者阻塞之后再启动下一个，确定阻塞顺序
+			waitFor(t, func() bool { return q.ParkStats().Parks == uint64(w+1) })
+		}
+		q.Enqueue(1)
+		if w := <-got; w != tc.want {
+			t.Fatalf("order %d: want consumer %d woken first, got %d", tc.order, tc.want, w)
+		}
+		q.Close()
+	}
+}